@@ -0,0 +1,117 @@
+package bonds
+
+import (
+	"kurse/money"
+	"kurse/portfolio"
+	"math"
+	"time"
+)
+
+// Percent converts quotes in percent of the nominal value into a factor.
+var Percent = money.NewFromFloat(0.01)
+
+type Coupon struct {
+	Date time.Time
+	Rate money.Decimal // per unit of nominal value
+}
+
+// Coupons returns the coupon dates after the given time up to and including maturity.
+func Coupons(bond portfolio.Bond, after time.Time) []Coupon {
+	coupons := make([]Coupon, 0)
+	rate := couponRate(bond)
+	for k := 0; ; k++ {
+		date := couponDate(bond, k)
+		if !date.After(after) {
+			break
+		}
+		coupons = append([]Coupon{{Date: date, Rate: rate}}, coupons...)
+	}
+	return coupons
+}
+
+// PreviousCoupon returns the last coupon date not after the given time.
+func PreviousCoupon(bond portfolio.Bond, at time.Time) time.Time {
+	previous, _ := couponPeriod(bond, at)
+	return previous
+}
+
+// couponPeriod returns the last coupon date not after the given time and the coupon date following it.
+func couponPeriod(bond portfolio.Bond, at time.Time) (previous time.Time, next time.Time) {
+	previous = bond.Maturity
+	k := 0
+	for previous.After(at) {
+		k++
+		previous = couponDate(bond, k)
+	}
+	return previous, couponDate(bond, k-1)
+}
+
+// couponDate returns the coupon date k periods before maturity, with the day clamped to the length of the month, so
+// a bond maturing on 31 August pays on the last day of February.
+func couponDate(bond portfolio.Bond, k int) time.Time {
+	maturity := bond.Maturity
+	first := time.Date(maturity.Year(), maturity.Month()-time.Month(k*monthsPerPeriod(bond)), 1, maturity.Hour(), maturity.Minute(), maturity.Second(), maturity.Nanosecond(), maturity.Location())
+	day := maturity.Day()
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// AccruedInterest is the interest per unit of nominal value accrued since the previous coupon (act/act).
+func AccruedInterest(bond portfolio.Bond, at time.Time) money.Decimal {
+	if !at.Before(bond.Maturity) {
+		return money.Zero
+	}
+	previous, next := couponPeriod(bond, at)
+	elapsed := money.NewFromInt(int64(days(previous, at)))
+	period := money.NewFromInt(int64(days(previous, next)))
+	fraction, _ := elapsed.Div(period)
+	return couponRate(bond).Mul(fraction)
+}
+
+// YieldToMaturity solves the annual yield, compounded per coupon period, at which the remaining cash flows per unit
+// of nominal value are worth the dirty price (clean price plus accrued interest, per unit of nominal value).
+func YieldToMaturity(bond portfolio.Bond, dirtyPrice float64, at time.Time) (float64, bool) {
+	coupons := Coupons(bond, at)
+	if len(coupons) == 0 || dirtyPrice <= 0 {
+		return 0, false
+	}
+	frequency := float64(bond.Payments())
+	presentValue := func(y float64) float64 {
+		pv := 0.0
+		for idx, coupon := range coupons {
+			cashFlow := coupon.Rate.Float64()
+			if idx == len(coupons)-1 {
+				cashFlow += 1
+			}
+			years := float64(days(at, coupon.Date)) / 365.25
+			pv += cashFlow / math.Pow(1+y/frequency, years*frequency)
+		}
+		return pv
+	}
+	low, high := -0.99*frequency, 10.0
+	if presentValue(low) < dirtyPrice || presentValue(high) > dirtyPrice {
+		return 0, false
+	}
+	for i := 0; i < 200; i++ {
+		mid := (low + high) / 2
+		if presentValue(mid) > dirtyPrice {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2, true
+}
+
+func couponRate(bond portfolio.Bond) money.Decimal {
+	rate, _ := money.NewFromFloat(bond.Coupon).Mul(Percent).Div(money.NewFromInt(int64(bond.Payments())))
+	return rate
+}
+
+func monthsPerPeriod(bond portfolio.Bond) int { return 12 / bond.Payments() }
+
+func days(from time.Time, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
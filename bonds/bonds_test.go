@@ -0,0 +1,112 @@
+package bonds
+
+import (
+	"kurse/money"
+	"kurse/portfolio"
+	"math"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestCoupons(t *testing.T) {
+	tests := []struct {
+		name  string
+		bond  portfolio.Bond
+		after string
+		want  []string
+	}{
+		{"annual", portfolio.Bond{Coupon: 2, Frequency: 1, Maturity: date("2029-11-15")}, "2026-10-19", []string{"2026-11-15", "2027-11-15", "2028-11-15", "2029-11-15"}},
+		{"semi-annual end of month", portfolio.Bond{Coupon: 2, Frequency: 2, Maturity: date("2030-08-31")}, "2027-01-01", []string{"2027-02-28", "2027-08-31", "2028-02-29", "2028-08-31", "2029-02-28", "2029-08-31", "2030-02-28", "2030-08-31"}},
+		{"quarterly end of month", portfolio.Bond{Coupon: 4, Frequency: 4, Maturity: date("2027-05-31")}, "2026-10-19", []string{"2026-11-30", "2027-02-28", "2027-05-31"}},
+		{"coupon date itself is excluded", portfolio.Bond{Coupon: 1, Frequency: 1, Maturity: date("2028-03-01")}, "2027-03-01", []string{"2028-03-01"}},
+		{"matured", portfolio.Bond{Coupon: 1, Frequency: 1, Maturity: date("2020-03-01")}, "2026-10-19", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coupons := Coupons(tt.bond, date(tt.after))
+			if len(coupons) != len(tt.want) {
+				t.Fatalf("got %d coupons, want %d: %v", len(coupons), len(tt.want), coupons)
+			}
+			for idx, coupon := range coupons {
+				if got := coupon.Date.Format("2006-01-02"); got != tt.want[idx] {
+					t.Errorf("coupon %d on %s, want %s", idx, got, tt.want[idx])
+				}
+			}
+		})
+	}
+}
+
+func TestPreviousCoupon(t *testing.T) {
+	bond := portfolio.Bond{Coupon: 2, Frequency: 2, Maturity: date("2030-08-31")}
+	tests := []struct {
+		at   string
+		want string
+	}{
+		{"2027-03-15", "2027-02-28"},
+		{"2027-02-28", "2027-02-28"},
+		{"2027-02-27", "2026-08-31"},
+		{"2028-03-01", "2028-02-29"},
+	}
+	for _, tt := range tests {
+		if got := PreviousCoupon(bond, date(tt.at)).Format("2006-01-02"); got != tt.want {
+			t.Errorf("PreviousCoupon(%s) = %s, want %s", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestAccruedInterest(t *testing.T) {
+	tests := []struct {
+		name string
+		bond portfolio.Bond
+		at   string
+		want string
+	}{
+		// 2026-08-31 to 2027-02-28 are 181 days, 50 of them elapsed: 1% * 50 / 181
+		{"end of month", portfolio.Bond{Coupon: 2, Frequency: 2, Maturity: date("2030-08-31")}, "2026-10-20", "0.00276243"},
+		// 2025-11-15 to 2026-11-15 are 365 days, 338 of them elapsed: 2.1% * 338 / 365
+		{"annual", portfolio.Bond{Coupon: 2.1, Frequency: 1, Maturity: date("2029-11-15")}, "2026-10-19", "0.01944658"},
+		{"on coupon date", portfolio.Bond{Coupon: 2, Frequency: 1, Maturity: date("2029-11-15")}, "2026-11-15", "0.00000000"},
+		{"matured", portfolio.Bond{Coupon: 2, Frequency: 1, Maturity: date("2020-11-15")}, "2026-10-19", "0.00000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AccruedInterest(tt.bond, date(tt.at)).StringFixed(8); got != tt.want {
+				t.Errorf("AccruedInterest = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestYieldToMaturity(t *testing.T) {
+	bond := portfolio.Bond{Coupon: 3, Frequency: 1, Maturity: date("2029-11-15")}
+	at := date("2026-11-15")
+	tests := []struct {
+		name  string
+		price float64
+		want  float64
+	}{
+		{"at par", 1, 0.03},
+		{"below par", 0.97, 0.0408},
+		{"above par", 1.03, 0.0196},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dirty := money.NewFromFloat(tt.price).Add(AccruedInterest(bond, at)).Float64()
+			got, ok := YieldToMaturity(bond, dirty, at)
+			if !ok || math.Abs(got-tt.want) > 0.0005 {
+				t.Errorf("YieldToMaturity(%v) = %v, %v, want %v", tt.price, got, ok, tt.want)
+			}
+		})
+	}
+	if _, ok := YieldToMaturity(bond, 0, at); ok {
+		t.Error("YieldToMaturity without price must not be known")
+	}
+}
@@ -2,52 +2,79 @@ package cached
 
 import (
 	"errors"
-	"kurse/lang"
 	"os"
 	"path"
 	"time"
 )
 
-var (
-	cacheDir string
-)
-
-func init() {
-	var err error
-	cacheDir, err = os.UserCacheDir()
-	lang.FatalOnError(err)
-}
-
-func Load[T any](application string, cache string, maxAge time.Duration, mapper func([]byte) *T) (*T, bool) {
+func Load[T any](application string, cache string, maxAge time.Duration, mapper func([]byte) (*T, error)) (*T, bool, error) {
 	var (
-		fi   os.FileInfo
-		data []byte
-		err  error
+		fi        os.FileInfo
+		data      []byte
+		err       error
+		cacheFile string
 	)
-	cacheFile := ensureCacheFile(application, cache)
+	if cacheFile, err = ensureCacheFile(application, cache); err != nil {
+		return nil, false, err
+	}
 	fi, err = os.Stat(cacheFile)
 	if errors.Is(err, os.ErrNotExist) {
-		return nil, false
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
 	}
 	age := time.Now().Sub(fi.ModTime())
 	if age > maxAge {
-		return nil, false
+		return nil, false, nil
 	}
-	data, err = os.ReadFile(cacheFile)
-	lang.FatalOnError(err)
-	return mapper(data), true
+	if data, err = os.ReadFile(cacheFile); err != nil {
+		return nil, false, err
+	}
+	obj, err := mapper(data)
+	if err != nil {
+		return nil, false, err
+	}
+	return obj, true, nil
 }
 
-func Save[T any](application string, cache string, obj *T, mapper func(*T) []byte) {
-	cacheFile := ensureCacheFile(application, cache)
-	data := mapper(obj)
-	err := os.WriteFile(cacheFile, data, 0644)
-	lang.FatalOnError(err)
+// ModTime returns when the cache was written last, ok is false if there is no cache.
+func ModTime(application string, cache string) (modified time.Time, ok bool, err error) {
+	var (
+		fi        os.FileInfo
+		cacheFile string
+	)
+	if cacheFile, err = ensureCacheFile(application, cache); err != nil {
+		return
+	}
+	fi, err = os.Stat(cacheFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return modified, false, nil
+	} else if err != nil {
+		return
+	}
+	return fi.ModTime(), true, nil
 }
 
-func ensureCacheFile(application, cache string) string {
+func Save[T any](application string, cache string, obj *T, mapper func(*T) ([]byte, error)) error {
+	cacheFile, err := ensureCacheFile(application, cache)
+	if err != nil {
+		return err
+	}
+	data, err := mapper(obj)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(cacheFile, data, 0644)
+}
+
+func ensureCacheFile(application, cache string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
 	dir := path.Join(cacheDir, application)
-	err := os.MkdirAll(dir, 0744)
-	lang.FatalOnError(err)
-	return path.Join(dir, cache)
+	if err = os.MkdirAll(dir, 0744); err != nil {
+		return "", err
+	}
+	return path.Join(dir, cache), nil
 }
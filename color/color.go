@@ -51,8 +51,10 @@ func colorize(color string, s any) string {
 	}
 }
 
-func InRed(s any) string   { return colorize(Red, s) }
-func InGreen(s any) string { return colorize(Green, s) }
+func InRed(s any) string    { return colorize(Red, s) }
+func InGreen(s any) string  { return colorize(Green, s) }
+func InGray(s any) string   { return colorize(Gray, s) }
+func InYellow(s any) string { return colorize(Yellow, s) }
 
 func ByAmount(amount float64, format string) string {
 	str := fmt.Sprintf(format, amount)
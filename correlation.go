@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"kurse/color"
+	"kurse/history"
+	"kurse/risk"
+	"strconv"
+	"time"
+)
+
+const defaultCorrelationDays = 90
+
+// correlationWindow returns the number of days given as argument, at least two are needed for a correlation.
+func correlationWindow(arg string) (int, error) {
+	if arg == "" {
+		return defaultCorrelationDays, nil
+	}
+	days, err := strconv.Atoi(arg)
+	if err != nil || days < 2 {
+		return 0, fmt.Errorf("invalid number of days '%s', use 'kurse correlation [days]' with at least 2 days", arg)
+	}
+	return days, nil
+}
+
+func printCorrelation(out Out, snapshots history.Snapshots, days int) {
+	snapshots = snapshots.Since(time.Now().AddDate(0, 0, -days))
+	symbols := make([]string, 0)
+	returns := make(map[string]risk.Series)
+	for _, symbol := range snapshots.Symbols() {
+		r := risk.Returns(snapshots.Prices(symbol))
+		if len(r) >= 2 {
+			symbols = append(symbols, symbol)
+			returns[symbol] = r
+		}
+	}
+	if len(symbols) < 2 {
+		out.Printf("Korrelation: zu wenige Kurse in den letzten %d Tagen (%d Snapshots, siehe 'kurse snapshot')\n", days, len(snapshots))
+		return
+	}
+	out.Printf("Korrelation der täglichen Renditen (%d Tage, %d Snapshots):\n", days, len(snapshots))
+	width := 0
+	for _, symbol := range symbols {
+		if len(symbol) > width {
+			width = len(symbol)
+		}
+	}
+	out.Printf("%*s", width, "")
+	for idx := range symbols {
+		out.Printf(" %6d", idx+1)
+	}
+	out.Println()
+	for row, a := range symbols {
+		out.Printf("%-*s", width, a)
+		for _, b := range symbols {
+			c, ok := risk.Correlation(returns[a], returns[b])
+			out.Printf(" %s", colorCorrelation(c, ok))
+		}
+		out.Printf("  (%d)\n", row+1)
+	}
+}
+
+func colorCorrelation(c float64, ok bool) string {
+	if !ok {
+		return fmt.Sprintf("%6s", "-")
+	}
+	str := fmt.Sprintf("%+6.2f", c)
+	switch {
+	case c >= 0.8:
+		return color.RedBackground + color.Black + str + color.Reset
+	case c >= 0.5:
+		return color.Yellow + str + color.Reset
+	case c <= -0.5:
+		return color.Blue + str + color.Reset
+	default:
+		return color.InGreen(str)
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"kurse/exchangerates"
+	"kurse/money"
+	"kurse/portfolio"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const unknownBroker = "unbekannt"
+
+type costs struct {
+	invested  money.Money
+	provision money.Money
+	fee       money.Money
+}
+
+func newCosts(currency string) *costs {
+	return &costs{
+		invested:  money.Nothing(currency),
+		provision: money.Nothing(currency),
+		fee:       money.Nothing(currency),
+	}
+}
+
+func (c costs) orderCosts() (money.Money, error) {
+	return c.provision.Add(c.fee)
+}
+
+func (c *costs) add(order portfolio.Order, fx *exchangerates.History) (err error) {
+	var price, provision, fee money.Money
+	currency := c.invested.Currency
+	if price, err = fx.Convert(money.New(order.Price, order.Currency), currency, order.Date); err != nil {
+		return err
+	}
+	if provision, err = fx.Convert(money.New(order.Provision, order.Currency), currency, order.Date); err != nil {
+		return err
+	}
+	if fee, err = fx.Convert(money.New(order.Fee, order.Currency), currency, order.Date); err != nil {
+		return err
+	}
+	if c.invested, err = c.invested.Add(price); err != nil {
+		return err
+	}
+	if c.provision, err = c.provision.Add(provision); err != nil {
+		return err
+	}
+	c.fee, err = c.fee.Add(fee)
+	return err
+}
+
+// terDrag estimates the fund costs of the order until now, based on the invested capital and the annual TER in percent.
+func terDrag(order portfolio.Order, ter float64, now time.Time, fx *exchangerates.History, currency string) (money.Money, error) {
+	years := now.Sub(order.Date).Hours() / 24 / 365.25
+	if years <= 0 {
+		return money.Nothing(currency), nil
+	}
+	invested, err := fx.Convert(money.New(order.Price, order.Currency), currency, order.Date)
+	if err != nil {
+		return invested, err
+	}
+	return invested.Mul(money.NewFromFloat(ter / 100 * years)).Round(), nil
+}
+
+func printCosts(out Out, stocks map[portfolio.Symbol]portfolio.Stock, fx *exchangerates.History, currency string) error {
+	var err error
+	now := time.Now()
+	total := newCosts(currency)
+	byBroker := make(map[string]*costs)
+	byYear := make(map[int]*costs)
+	terDrags := make(map[portfolio.Symbol]money.Money)
+	terDragSum := money.Nothing(currency)
+	for _, stock := range stocks {
+		for _, order := range stock.Orders {
+			broker := stock.BrokerOf(order)
+			if broker == "" {
+				broker = unknownBroker
+			}
+			if _, ok := byBroker[broker]; !ok {
+				byBroker[broker] = newCosts(currency)
+			}
+			if _, ok := byYear[order.Date.Year()]; !ok {
+				byYear[order.Date.Year()] = newCosts(currency)
+			}
+			for _, c := range []*costs{byBroker[broker], byYear[order.Date.Year()], total} {
+				if err = c.add(order, fx); err != nil {
+					return fmt.Errorf("unable to add order of %s: %w", stock.Symbol, err)
+				}
+			}
+			if stock.Ter > 0 {
+				var drag money.Money
+				if drag, err = terDrag(order, stock.Ter, now, fx, currency); err != nil {
+					return fmt.Errorf("unable to estimate TER of %s: %w", stock.Symbol, err)
+				}
+				if _, ok := terDrags[stock.Symbol]; !ok {
+					terDrags[stock.Symbol] = money.Nothing(currency)
+				}
+				if terDrags[stock.Symbol], err = terDrags[stock.Symbol].Add(drag); err != nil {
+					return err
+				}
+				if terDragSum, err = terDragSum.Add(drag); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	out.Println("Ordergebühren je Broker:")
+	brokers := make([]string, 0, len(byBroker))
+	for broker := range byBroker {
+		brokers = append(brokers, broker)
+	}
+	sort.Strings(brokers)
+	for _, broker := range brokers {
+		if err = printCostLine(out, broker, *byBroker[broker]); err != nil {
+			return err
+		}
+	}
+	out.Println()
+
+	out.Println("Ordergebühren je Jahr:")
+	years := make([]int, 0, len(byYear))
+	for year := range byYear {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+	for _, year := range years {
+		if err = printCostLine(out, strconv.Itoa(year), *byYear[year]); err != nil {
+			return err
+		}
+	}
+	out.Println()
+
+	if err = printCostLine(out, "Summe", *total); err != nil {
+		return err
+	}
+
+	if len(terDrags) > 0 {
+		out.Println()
+		out.Println("Geschätzte laufende Fondskosten (TER) seit Kauf:")
+		symbols := make([]string, 0, len(terDrags))
+		for symbol := range terDrags {
+			symbols = append(symbols, string(symbol))
+		}
+		sort.Strings(symbols)
+		for _, symbol := range symbols {
+			drag := terDrags[portfolio.Symbol(symbol)]
+			out.Printf("  %-20v %10.2f %s (TER %.2f%% p.a.)\n", symbol, drag.Float64(), drag.Currency, stocks[portfolio.Symbol(symbol)].Ter)
+		}
+		out.Printf("  %-20v %10.2f %s\n", "Summe", terDragSum.Float64(), terDragSum.Currency)
+		orderCosts, err := total.orderCosts()
+		if err != nil {
+			return err
+		}
+		overall, err := orderCosts.Add(terDragSum)
+		if err != nil {
+			return err
+		}
+		out.Printf("  %-20v %10.2f %s\n", "Gesamtkosten", overall.Float64(), overall.Currency)
+	}
+	return nil
+}
+
+func printCostLine(out Out, name any, c costs) error {
+	orderCosts, err := c.orderCosts()
+	if err != nil {
+		return err
+	}
+	percent := 0.0
+	if c.invested.Sign() != 0 {
+		percent = money.Percent(orderCosts.Amount, c.invested.Amount)
+	}
+	out.Printf("  %-20[1]v %10.2[2]f %[7]s von %10.2[3]f %[7]s (%.2[4]f%%) | Provision: %10.2[5]f %[7]s | Gebühren: %10.2[6]f %[7]s\n", name, orderCosts.Float64(), c.invested.Float64(), percent, c.provision.Float64(), c.fee.Float64(), c.invested.Currency)
+	return nil
+}
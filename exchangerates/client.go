@@ -1,71 +1,209 @@
 package exchangerates
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"kurse/cached"
+	"kurse/httpclient"
 	"kurse/lang"
+	"kurse/markets"
+	"kurse/money"
 	"kurse/portfolio"
+	"log"
+	"math"
 	"net/http"
 	"time"
 )
 
-const freeCurrencyApiUrl = "https://api.freecurrencyapi.com/v1/latest?apikey=%s&base_currency=EUR"
+const (
+	FreecurrencyApiName = "freecurrencyapi"
+	freeCurrencyApiUrl  = "https://api.freecurrencyapi.com/v1/latest?apikey=%s&base_currency=%s"
+)
+
+// Provider delivers the current exchange rates with the day they were published.
+type Provider interface {
+	Name() string
+	FetchExchangeRates(ctx context.Context) (DailyRates, error)
+}
+
+// NewProvider creates the provider with the given name, the default is freecurrencyapi.
+func NewProvider(name string, secrets portfolio.Secrets, base string, options httpclient.Options) (Provider, error) {
+	switch name {
+	case "", FreecurrencyApiName:
+		return NewClient(secrets.FreecurrencyApiKey, base, options), nil
+	case EcbName:
+		return NewEcb(options), nil
+	default:
+		return nil, fmt.Errorf("unknown exchange rate provider '%s', use one of: %s, %s", name, FreecurrencyApiName, EcbName)
+	}
+}
 
 type Client struct {
-	client http.Client
+	client *httpclient.Client
 	apiKey string
+	base   string
 }
 
-func NewClient(apiKey string, timeout time.Duration) *Client {
+// NewClient creates a freecurrencyapi client fetching the rates per unit of the base currency.
+func NewClient(apiKey string, base string, options httpclient.Options) *Client {
 	return &Client{
-		client: http.Client{Timeout: timeout},
+		client: httpclient.New(FreecurrencyApiName, options),
 		apiKey: apiKey,
+		base:   base,
 	}
 }
 
+// Rates holds the amount of each currency per unit of the base currency, which is contained with 1. Rates stored
+// before the base currency was configurable lack it and are per EUR.
 type Rates struct {
 	Data map[string]float64 `json:"data"`
+	Base string             `json:"base,omitempty"`
+	// Stale is the time outdated rates were fetched, if they had to be taken from the cache because fetching failed
+	Stale time.Time `json:"-"`
+}
+
+// Rate returns the factor converting an amount in from into currency, subunits like GBp are converted via their
+// ISO 4217 currency.
+func (rates Rates) Rate(from string, currency string) (money.Decimal, bool) {
+	if from == currency {
+		return money.NewFromInt(1), true
+	}
+	from, fromPer := money.Subunit(from)
+	currency, toPer := money.Subunit(currency)
+	scale, _ := money.NewFromInt(toPer).Div(money.NewFromInt(fromPer))
+	if from == currency {
+		return scale, true
+	}
+	fromRate, fok := rates.perBase(from)
+	toRate, tok := rates.perBase(currency)
+	if !fok || !tok {
+		return money.Zero, false
+	}
+	rate, ok := money.NewFromFloat(toRate).Div(money.NewFromFloat(fromRate))
+	return rate.Mul(scale), ok
+}
+
+func (rates Rates) perBase(currency string) (float64, bool) {
+	if rate, ok := rates.Data[currency]; ok {
+		return rate, true
+	}
+	if currency == rates.Base || currency == "EUR" && rates.legacy() {
+		return 1, true
+	}
+	return 0, false
+}
+
+// legacy reports whether the rates were stored before the base currency was configurable, they are per EUR then.
+func (rates Rates) legacy() bool {
+	if rates.Base != "" {
+		return false
+	}
+	for _, rate := range rates.Data {
+		if rate == 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// Convert converts the amount into the currency and rounds it to the currency's minor unit.
+func (rates Rates) Convert(amount money.Money, currency string) (money.Money, bool) {
+	rate, ok := rates.Rate(amount.Currency, currency)
+	if !ok {
+		return amount, false
+	}
+	return amount.Convert(rate, currency).Round(), true
 }
 
-func FetchExchangeRates(secrets portfolio.Secrets, useCache bool) Rates {
-	if useCache {
-		r, ok := cached.Load("kurse", "exchangerates", 24*time.Hour, func(data []byte) *Rates {
-			r := &Rates{}
-			e := json.Unmarshal(data, r)
-			lang.FatalOnError(e)
-			return r
-		})
-		if ok {
-			return *r
+// FetchExchangeRates returns the cached rates if still fresh according to ttl and the currency market, otherwise the
+// rates of the provider. If fetching fails, the cached rates are used regardless of their age, marked as stale.
+func FetchExchangeRates(ctx context.Context, provider Provider, ttl markets.TTL, useCache bool) (Rates, error) {
+	if useCache && fresh(ttl, time.Now()) {
+		if r, ok := loadCache(); ok {
+			return *r, nil
+		}
+	}
+	daily, err := provider.FetchExchangeRates(ctx)
+	rates := daily.Rates
+	if err != nil {
+		if r, ok := loadCache(); ok {
+			if modified, ok, _ := cached.ModTime("kurse", "exchangerates"); ok {
+				r.Stale = modified
+			}
+			log.Printf("%v, using outdated cached exchange rates\n", err)
+			return *r, nil
 		}
+		return rates, err
 	}
-	client := NewClient(secrets.FreecurrencyApiKey, 10*time.Second)
-	rates, err := client.FetchExchangeRates()
-	lang.FatalOnError(err)
-	cached.Save("kurse", "exchangerates", &rates, func(rates *Rates) (data []byte) {
-		data, err = json.MarshalIndent(rates, "", "  ")
-		lang.FatalOnError(err)
-		return
+	if history, err := LoadHistory(); err != nil {
+		log.Printf("%v, not adding the rates to it\n", err)
+	} else {
+		history.Add(daily.Date, rates)
+		if err = history.Save(); err != nil {
+			log.Printf("unable to save exchange rate history: %v\n", err)
+		}
+	}
+	err = cached.Save("kurse", "exchangerates", &rates, func(rates *Rates) ([]byte, error) {
+		return json.MarshalIndent(rates, "", "  ")
 	})
-	return rates
+	if err != nil {
+		log.Printf("unable to cache exchange rates: %v\n", err)
+	}
+	return rates, nil
 }
 
-func (client *Client) FetchExchangeRates() (Rates, error) { return client.fetchExchangeRates() }
+func fresh(ttl markets.TTL, now time.Time) bool {
+	modified, ok, err := cached.ModTime("kurse", "exchangerates")
+	if err != nil {
+		log.Printf("unable to check exchange rate cache: %v\n", err)
+	}
+	return ok && now.Before(markets.Forex.Expires(modified, ttl))
+}
 
-func (client *Client) fetchExchangeRates() (rates Rates, err error) {
+// loadCache returns the cached rates regardless of their age, an unreadable cache counts as missing.
+func loadCache() (*Rates, bool) {
+	r, ok, err := cached.Load("kurse", "exchangerates", math.MaxInt64, func(data []byte) (*Rates, error) {
+		r := &Rates{}
+		return r, json.Unmarshal(data, r)
+	})
+	if err != nil {
+		log.Printf("ignoring unreadable exchange rate cache: %v\n", err)
+		return nil, false
+	}
+	return r, ok
+}
+
+func (client *Client) Name() string { return FreecurrencyApiName }
+
+// FetchExchangeRates returns the latest rates of freecurrencyapi, which are current and therefore dated today.
+func (client *Client) FetchExchangeRates(ctx context.Context) (DailyRates, error) {
+	rates, err := client.fetchExchangeRates(ctx)
+	return DailyRates{Date: time.Now(), Rates: rates}, err
+}
+
+func (client *Client) fetchExchangeRates(ctx context.Context) (rates Rates, err error) {
 	var (
 		rq *http.Request
 		rs *http.Response
 	)
-	url := fmt.Sprintf(freeCurrencyApiUrl, client.apiKey)
-	if rq, err = http.NewRequest(http.MethodGet, url, nil); err != nil {
+	url := fmt.Sprintf(freeCurrencyApiUrl, client.apiKey, client.base)
+	if rq, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil); err != nil {
 		return rates, err
 	}
 	if rs, err = client.client.Do(rq); err != nil {
 		return rates, err
 	}
 	defer lang.Close(rs.Body, "unable to close response body")
-	err = json.NewDecoder(rs.Body).Decode(&rates)
-	return rates, err
+	if rs.StatusCode != http.StatusOK {
+		return rates, fmt.Errorf("%s responded with %s", FreecurrencyApiName, rs.Status)
+	}
+	if err = json.NewDecoder(rs.Body).Decode(&rates); err != nil {
+		return rates, err
+	}
+	if rates.Data != nil {
+		rates.Data[client.base] = 1
+		rates.Base = client.base
+	}
+	return rates, nil
 }
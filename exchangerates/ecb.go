@@ -0,0 +1,110 @@
+package exchangerates
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"kurse/httpclient"
+	"kurse/lang"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	EcbName          = "ecb"
+	ecbDailyUrl      = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+	ecbHistoricalUrl = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist.xml"
+	ecbDateLayout    = "2006-01-02"
+)
+
+// Ecb fetches the euro foreign exchange reference rates of the european central bank, no api key needed.
+type Ecb struct {
+	client *httpclient.Client
+}
+
+func NewEcb(options httpclient.Options) *Ecb {
+	return &Ecb{client: httpclient.New(EcbName, options)}
+}
+
+// DailyRates are the rates published for a single day.
+type DailyRates struct {
+	Date  time.Time
+	Rates Rates
+}
+
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+func (ecb *Ecb) Name() string { return EcbName }
+
+// FetchExchangeRates returns the latest reference rates, dated the day the ecb published them.
+func (ecb *Ecb) FetchExchangeRates(ctx context.Context) (DailyRates, error) {
+	days, err := ecb.fetch(ctx, ecbDailyUrl)
+	if err != nil {
+		return DailyRates{}, err
+	}
+	if len(days) == 0 {
+		return DailyRates{}, fmt.Errorf("no exchange rates in %s", ecbDailyUrl)
+	}
+	return days[len(days)-1], nil
+}
+
+// FetchHistoricalRates fetches all reference rates published since 1999, ordered by date.
+func (ecb *Ecb) FetchHistoricalRates(ctx context.Context) ([]DailyRates, error) {
+	return ecb.fetch(ctx, ecbHistoricalUrl)
+}
+
+func (ecb *Ecb) fetch(ctx context.Context, url string) (days []DailyRates, err error) {
+	var (
+		rq *http.Request
+		rs *http.Response
+	)
+	if rq, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil); err != nil {
+		return nil, err
+	}
+	if rs, err = ecb.client.Do(rq); err != nil {
+		return nil, err
+	}
+	defer lang.Close(rs.Body, "unable to close response body")
+	if rs.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with %s", url, rs.Status)
+	}
+	return ParseEcb(rs.Body)
+}
+
+// ParseEcb parses the daily or historical reference rate xml of the european central bank.
+func ParseEcb(reader io.Reader) ([]DailyRates, error) {
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(reader).Decode(&envelope); err != nil {
+		return nil, err
+	}
+	days := make([]DailyRates, 0, len(envelope.Days))
+	for _, day := range envelope.Days {
+		date, err := time.Parse(ecbDateLayout, day.Time)
+		if err != nil {
+			return nil, err
+		}
+		rates := Rates{Data: make(map[string]float64, len(day.Rates)+1), Base: "EUR"}
+		rates.Data["EUR"] = 1
+		for _, rate := range day.Rates {
+			value, err := strconv.ParseFloat(rate.Rate, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rate of %s on %s: %w", rate.Currency, day.Time, err)
+			}
+			rates.Data[rate.Currency] = value
+		}
+		days = append(days, DailyRates{Date: date, Rates: rates})
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date.Before(days[j].Date) })
+	return days, nil
+}
@@ -0,0 +1,127 @@
+package exchangerates
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse(historyDateLayout, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestRate(t *testing.T) {
+	perEur := Rates{Data: map[string]float64{"EUR": 1, "GBP": 0.85, "ZAR": 20, "USD": 1.1}, Base: "EUR"}
+	perChf := Rates{Data: map[string]float64{"CHF": 1, "EUR": 0.95}, Base: "CHF"}
+	legacy := Rates{Data: map[string]float64{"GBP": 0.85, "USD": 1.1}}
+	tests := []struct {
+		name  string
+		rates Rates
+		from  string
+		to    string
+		want  string
+		known bool
+	}{
+		{"same currency", perEur, "USD", "USD", "1.000000", true},
+		{"per base", perEur, "EUR", "USD", "1.100000", true},
+		{"cross rate", perEur, "USD", "GBP", "0.772727", true},
+		{"pence to euro", perEur, "GBp", "EUR", "0.011765", true},
+		{"euro to pence", perEur, "EUR", "GBX", "85.000000", true},
+		{"south african cents to rand", perEur, "ZAc", "ZAR", "0.010000", true},
+		{"south african cents to euro", perEur, "ZAC", "EUR", "0.000500", true},
+		{"pence to pound", perEur, "GBp", "GBP", "0.010000", true},
+		{"pound to pence", perEur, "GBP", "GBp", "100.000000", true},
+		{"pence to pence", perEur, "GBp", "GBX", "1.000000", true},
+		{"subunit of unknown currency", perEur, "ILA", "EUR", "0.000000", false},
+		{"unknown target", perEur, "EUR", "CHF", "0.000000", false},
+		{"unknown source", perEur, "CHF", "USD", "0.000000", false},
+		{"other base", perChf, "EUR", "CHF", "1.052632", true},
+		{"euro missing in other base", Rates{Data: map[string]float64{"CHF": 1, "GBP": 0.9}}, "EUR", "CHF", "0.000000", false},
+		{"legacy rates per euro", legacy, "EUR", "USD", "1.100000", true},
+		{"legacy pence to euro", legacy, "GBp", "EUR", "0.011765", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, known := tt.rates.Rate(tt.from, tt.to)
+			if known != tt.known || rate.StringFixed(6) != tt.want {
+				t.Errorf("Rate(%s, %s) = %s, %v, want %s, %v", tt.from, tt.to, rate.StringFixed(6), known, tt.want, tt.known)
+			}
+		})
+	}
+}
+
+const ecbXml = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2026-10-16">
+			<Cube currency="USD" rate="1.1652"/>
+			<Cube currency="GBP" rate="0.8671"/>
+		</Cube>
+		<Cube time="2026-10-15">
+			<Cube currency="USD" rate="1.1601"/>
+			<Cube currency="GBP" rate="0.8655"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func TestParseEcb(t *testing.T) {
+	days, err := ParseEcb(strings.NewReader(ecbXml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(days) != 2 {
+		t.Fatalf("got %d days, want 2", len(days))
+	}
+	if !days[0].Date.Equal(date("2026-10-15")) || !days[1].Date.Equal(date("2026-10-16")) {
+		t.Errorf("days not ordered by date: %s, %s", days[0].Date, days[1].Date)
+	}
+	latest := days[1].Rates
+	if latest.Base != "EUR" || latest.Data["EUR"] != 1 || latest.Data["USD"] != 1.1652 || latest.Data["GBP"] != 0.8671 {
+		t.Errorf("unexpected rates of %s: %v", days[1].Date, latest)
+	}
+
+	for name, xml := range map[string]string{
+		"invalid rate": strings.Replace(ecbXml, `rate="1.1652"`, `rate="n/a"`, 1),
+		"invalid date": strings.Replace(ecbXml, `time="2026-10-16"`, `time="16.10.2026"`, 1),
+		"invalid xml":  ecbXml[:100],
+	} {
+		if _, err := ParseEcb(strings.NewReader(xml)); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestRatesAt(t *testing.T) {
+	history := &History{Days: map[string]map[string]float64{
+		"2026-10-15": {"EUR": 1, "USD": 1.1601},
+		"2026-10-16": {"EUR": 1, "USD": 1.1652},
+	}}
+	tests := []struct {
+		name  string
+		at    string
+		day   string
+		known bool
+	}{
+		{"day with rates", "2026-10-15", "2026-10-15", true},
+		{"weekend", "2026-10-18", "2026-10-16", true},
+		{"seven days later", "2026-10-23", "2026-10-16", true},
+		{"eight days later", "2026-10-24", "", false},
+		{"before first day", "2026-10-14", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates, day, ok := history.RatesAt(date(tt.at))
+			if ok != tt.known {
+				t.Fatalf("RatesAt(%s) known = %v, want %v", tt.at, ok, tt.known)
+			}
+			if ok && (day.Format(historyDateLayout) != tt.day || rates.Data["USD"] != history.Days[tt.day]["USD"]) {
+				t.Errorf("RatesAt(%s) = %v of %s, want rates of %s", tt.at, rates.Data, day.Format(historyDateLayout), tt.day)
+			}
+		})
+	}
+}
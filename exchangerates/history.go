@@ -0,0 +1,93 @@
+package exchangerates
+
+import (
+	"encoding/json"
+	"fmt"
+	"kurse/money"
+	"kurse/stored"
+	"sort"
+	"time"
+)
+
+const (
+	historyDateLayout = "2006-01-02"
+	// maxFallbackDays limits how many days a lookup goes back to find the previous business day.
+	maxFallbackDays = 7
+)
+
+// History is the local store of daily exchange rates, Days maps the date to the rates of the day, see Rates.
+type History struct {
+	Days map[string]map[string]float64 `json:"days"`
+}
+
+// LoadHistory returns the stored exchange rates, an empty history if there are none or they can't be read.
+func LoadHistory() (*History, error) {
+	history, ok, err := stored.Load("kurse", "exchangerates.json", func(data []byte) (*History, error) {
+		h := &History{}
+		return h, json.Unmarshal(data, h)
+	})
+	if err != nil {
+		return &History{Days: make(map[string]map[string]float64)}, fmt.Errorf("unable to load exchange rate history: %w", err)
+	}
+	if !ok || history.Days == nil {
+		return &History{Days: make(map[string]map[string]float64)}, nil
+	}
+	return history, nil
+}
+
+func (history *History) Save() error {
+	return stored.Save("kurse", "exchangerates.json", history, func(history *History) ([]byte, error) {
+		return json.Marshal(history)
+	})
+}
+
+func (history *History) Add(date time.Time, rates Rates) {
+	if len(rates.Data) == 0 {
+		return
+	}
+	day := make(map[string]float64, len(rates.Data))
+	for currency, rate := range rates.Data {
+		day[currency] = rate
+	}
+	history.Days[date.Format(historyDateLayout)] = day
+}
+
+// Range returns the first and last day of the store.
+func (history *History) Range() (first string, last string) {
+	days := make([]string, 0, len(history.Days))
+	for day := range history.Days {
+		days = append(days, day)
+	}
+	if len(days) == 0 {
+		return "", ""
+	}
+	sort.Strings(days)
+	return days[0], days[len(days)-1]
+}
+
+// RatesAt returns the rates of the date, falling back to the previous business days with known rates.
+func (history *History) RatesAt(date time.Time) (Rates, time.Time, bool) {
+	for fallback := 0; fallback <= maxFallbackDays; fallback++ {
+		day := date.AddDate(0, 0, -fallback)
+		if data, ok := history.Days[day.Format(historyDateLayout)]; ok {
+			return Rates{Data: data}, day, true
+		}
+	}
+	return Rates{}, date, false
+}
+
+// Convert converts the amount into the currency at the rates of the date.
+func (history *History) Convert(amount money.Money, currency string, date time.Time) (money.Money, error) {
+	if amount.Currency == currency {
+		return amount, nil
+	}
+	rates, _, ok := history.RatesAt(date)
+	if !ok {
+		return amount, fmt.Errorf("no exchange rates for %s, see 'kurse fx backfill'", date.Format(historyDateLayout))
+	}
+	converted, ok := rates.Convert(amount, currency)
+	if !ok {
+		return amount, fmt.Errorf("no exchange rate from %s to %s for %s", amount.Currency, currency, date.Format(historyDateLayout))
+	}
+	return converted, nil
+}
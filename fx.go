@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"kurse/exchangerates"
+	"kurse/httpclient"
+	"kurse/lang"
+	"os"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// fx handles 'kurse fx backfill [file]' and 'kurse fx <currency> [date]'.
+func fx(ctx context.Context, out Out, args []string, base string) error {
+	if len(args) == 0 {
+		return errors.New("use 'kurse fx backfill [file]' or 'kurse fx <currency> [YYYY-MM-DD]'")
+	}
+	history, err := exchangerates.LoadHistory()
+	if err != nil {
+		return err
+	}
+	if args[0] == "backfill" {
+		days, err := fetchHistoricalRates(ctx, args[1:])
+		if err != nil {
+			return err
+		}
+		for _, day := range days {
+			history.Add(day.Date, day.Rates)
+		}
+		if err = history.Save(); err != nil {
+			return err
+		}
+		first, last := history.Range()
+		out.Printf("%d Tage mit Umrechnungskursen übernommen, gespeichert sind %s bis %s (%d Tage)\n", len(days), first, last, len(history.Days))
+		return nil
+	}
+
+	currency := args[0]
+	date := time.Now()
+	if len(args) > 1 {
+		var err error
+		if date, err = time.Parse(dateLayout, args[1]); err != nil {
+			return err
+		}
+	}
+	rates, day, ok := history.RatesAt(date)
+	if !ok {
+		return errors.New("no exchange rates for " + date.Format(dateLayout) + ", see 'kurse fx backfill'")
+	}
+	rate, ok := rates.Rate(base, currency)
+	if !ok {
+		return errors.New("no exchange rate for " + currency + " on " + day.Format(dateLayout))
+	}
+	out.Printf("%s: 1 %s = %s %s\n", day.Format(dateLayout), base, rate.StringFixed(4), currency)
+	return nil
+}
+
+// fetchHistoricalRates reads the ecb historical reference rate xml from the file or fetches it from the ecb.
+func fetchHistoricalRates(ctx context.Context, args []string) ([]exchangerates.DailyRates, error) {
+	if len(args) == 0 {
+		return exchangerates.NewEcb(httpclient.Options{Timeout: time.Minute, Offline: *offline}).FetchHistoricalRates(ctx)
+	}
+	file, err := os.Open(args[0])
+	if err != nil {
+		return nil, err
+	}
+	defer lang.Close(file, "unable to close file")
+	return exchangerates.ParseEcb(file)
+}
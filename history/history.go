@@ -0,0 +1,160 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"kurse/money"
+	"kurse/risk"
+	"kurse/stored"
+	"sort"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+type Snapshots []Snapshot
+
+// Snapshot holds the values of a day, Value, Buy and Dividends of the snapshot and its positions are in Currency.
+type Snapshot struct {
+	Date      time.Time     `json:"date"`
+	Currency  string        `json:"currency"`
+	Value     money.Decimal `json:"value"`
+	Buy       money.Decimal `json:"buy"`
+	Dividends money.Decimal `json:"dividends"`
+	Positions []Position    `json:"positions"`
+	Benchmark *Position     `json:"benchmark,omitempty"`
+}
+
+// Position holds the values of a single stock, Price is in the quote currency Currency.
+type Position struct {
+	Symbol    string        `json:"symbol"`
+	Count     money.Decimal `json:"count"`
+	Price     money.Decimal `json:"price"`
+	Currency  string        `json:"currency"`
+	Value     money.Decimal `json:"value"`
+	Buy       money.Decimal `json:"buy"`
+	Dividends money.Decimal `json:"dividends"`
+}
+
+func (snapshot Snapshot) Day() string { return snapshot.Date.Format(dateLayout) }
+
+// Position returns the recorded position of the symbol, if it was held on that day.
+func (snapshot Snapshot) Position(symbol string) (Position, bool) {
+	for _, position := range snapshot.Positions {
+		if position.Symbol == symbol {
+			return position, true
+		}
+	}
+	return Position{}, false
+}
+
+func (snapshot Snapshot) Guv() money.Money {
+	return money.New(snapshot.Value.Sub(snapshot.Buy), snapshot.Currency)
+}
+
+func (snapshot Snapshot) GuvInklDividends() money.Money {
+	return money.New(snapshot.Value.Add(snapshot.Dividends).Sub(snapshot.Buy), snapshot.Currency)
+}
+
+func Load() (Snapshots, error) {
+	snapshots, ok, err := stored.Load("kurse", "history.json", func(data []byte) (*Snapshots, error) {
+		s := &Snapshots{}
+		return s, json.Unmarshal(data, s)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to load snapshots: %w", err)
+	}
+	if !ok {
+		return Snapshots{}, nil
+	}
+	return *snapshots, nil
+}
+
+// Record stores the snapshot, replacing an already recorded snapshot of the same day.
+func Record(snapshot Snapshot) (Snapshots, error) {
+	snapshots, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	replaced := false
+	for idx := range snapshots {
+		if snapshots[idx].Day() == snapshot.Day() {
+			snapshots[idx] = snapshot
+			replaced = true
+		}
+	}
+	if !replaced {
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Date.Before(snapshots[j].Date) })
+	err = stored.Save("kurse", "history.json", &snapshots, func(snapshots *Snapshots) ([]byte, error) {
+		return json.MarshalIndent(snapshots, "", "  ")
+	})
+	return snapshots, err
+}
+
+// Symbols returns all symbols recorded in any snapshot, sorted.
+func (snapshots Snapshots) Symbols() []string {
+	seen := make(map[string]bool)
+	symbols := make([]string, 0)
+	for _, snapshot := range snapshots {
+		for _, position := range snapshot.Positions {
+			if !seen[position.Symbol] {
+				seen[position.Symbol] = true
+				symbols = append(symbols, position.Symbol)
+			}
+		}
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Prices returns the recorded price series of the symbol in its quote currency.
+func (snapshots Snapshots) Prices(symbol string) risk.Series {
+	series := make(risk.Series, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		if position, ok := snapshot.Position(symbol); ok && position.Price.Sign() > 0 {
+			series = append(series, risk.Point{Date: snapshot.Date, Value: position.Price.Float64()})
+		}
+	}
+	return series
+}
+
+func (snapshots Snapshots) BenchmarkPrices() risk.Series {
+	series := make(risk.Series, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		if snapshot.Benchmark != nil && snapshot.Benchmark.Price.Sign() > 0 {
+			series = append(series, risk.Point{Date: snapshot.Date, Value: snapshot.Benchmark.Price.Float64()})
+		}
+	}
+	return series
+}
+
+// Returns calculates the daily returns of the depot value, adjusted by the money invested in between two snapshots.
+func (snapshots Snapshots) Returns() risk.Series {
+	if len(snapshots) < 2 {
+		return risk.Series{}
+	}
+	returns := make(risk.Series, 0, len(snapshots)-1)
+	for idx := 1; idx < len(snapshots); idx++ {
+		previous, current := snapshots[idx-1], snapshots[idx]
+		invested := current.Buy.Sub(previous.Buy)
+		ratio, ok := current.Value.Sub(invested).Div(previous.Value)
+		if !ok {
+			continue
+		}
+		returns = append(returns, risk.Point{Date: current.Date, Value: ratio.Float64() - 1})
+	}
+	return returns
+}
+
+// Since returns the snapshots taken at or after the given time.
+func (snapshots Snapshots) Since(since time.Time) Snapshots {
+	filtered := make(Snapshots, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		if !snapshot.Date.Before(since) {
+			filtered = append(filtered, snapshot)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,206 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"kurse/lang"
+	"kurse/quota"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Options configure retries and rate limiting, zero values fall back to the defaults.
+type Options struct {
+	Timeout    time.Duration `yaml:"timeout" json:"timeout"`
+	Retries    int           `yaml:"retries" json:"retries"`
+	MinBackoff time.Duration `yaml:"minBackoff" json:"minBackoff"`
+	MaxBackoff time.Duration `yaml:"maxBackoff" json:"maxBackoff"`
+	// MinRemaining stops calling a provider once it reports at most this many remaining calls
+	MinRemaining int `yaml:"minRemaining" json:"minRemaining"`
+	// ThrottleBelow delays calls by ThrottleDelay once a provider reports fewer remaining calls, -1 disables throttling
+	ThrottleBelow int           `yaml:"throttleBelow" json:"throttleBelow"`
+	ThrottleDelay time.Duration `yaml:"throttleDelay" json:"throttleDelay"`
+	// Offline refuses all calls with ErrOffline, it is set by the -offline flag
+	Offline bool `yaml:"-" json:"-"`
+}
+
+// ErrOffline is returned instead of calling a provider in offline mode.
+var ErrOffline = errors.New("offline")
+
+var DefaultOptions = Options{
+	Timeout:       10 * time.Second,
+	Retries:       3,
+	MinBackoff:    500 * time.Millisecond,
+	MaxBackoff:    30 * time.Second,
+	MinRemaining:  0,
+	ThrottleBelow: 10,
+	ThrottleDelay: time.Second,
+}
+
+func (options Options) withDefaults() Options {
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultOptions.Timeout
+	}
+	if options.Retries < 0 {
+		options.Retries = 0
+	} else if options.Retries == 0 {
+		options.Retries = DefaultOptions.Retries
+	}
+	if options.MinBackoff <= 0 {
+		options.MinBackoff = DefaultOptions.MinBackoff
+	}
+	if options.MaxBackoff < options.MinBackoff {
+		options.MaxBackoff = DefaultOptions.MaxBackoff
+	}
+	if options.ThrottleBelow < 0 {
+		options.ThrottleBelow = 0
+	} else if options.ThrottleBelow == 0 {
+		options.ThrottleBelow = DefaultOptions.ThrottleBelow
+	}
+	if options.ThrottleDelay <= 0 {
+		options.ThrottleDelay = DefaultOptions.ThrottleDelay
+	}
+	return options
+}
+
+// Client retries failed requests with exponential backoff and jitter, respects 429/Retry-After and keeps track of
+// the remaining calls the provider reports in the x-ratelimit-remaining header.
+type Client struct {
+	name      string
+	client    http.Client
+	options   Options
+	mutex     sync.Mutex
+	remaining int
+}
+
+func New(name string, options Options) *Client {
+	options = options.withDefaults()
+	return &Client{
+		name:      name,
+		client:    http.Client{Timeout: options.Timeout},
+		options:   options,
+		remaining: -1,
+	}
+}
+
+// Remaining returns the remaining calls reported by the provider, -1 if unknown.
+func (client *Client) Remaining() int {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	return client.remaining
+}
+
+func (client *Client) Do(rq *http.Request) (*http.Response, error) {
+	if client.options.Offline {
+		return nil, fmt.Errorf("%s: %w", client.name, ErrOffline)
+	}
+	var lastErr error
+	for attempt := 0; attempt <= client.options.Retries; attempt++ {
+		if err := client.throttle(rq); err != nil {
+			return nil, err
+		}
+		if err := quota.Check(client.name, time.Now()); err != nil {
+			return nil, err
+		}
+		rs, err := client.client.Do(rq.Clone(rq.Context()))
+		if err != nil {
+			lastErr = err
+			if rq.Context().Err() != nil {
+				return nil, err
+			}
+			client.wait(rq, attempt, client.backoff(attempt), err.Error())
+			continue
+		}
+		if err = quota.Record(client.name, rs.Header, time.Now()); err != nil {
+			log.Printf("unable to record call of %s: %v\n", client.name, err)
+		}
+		client.updateRemaining(rs)
+		if !retryable(rs.StatusCode) {
+			return rs, nil
+		}
+		lastErr = fmt.Errorf("%s responded with %s", client.name, rs.Status)
+		delay, ok := retryAfter(rs.Header.Get("Retry-After"), time.Now())
+		if !ok {
+			delay = client.backoff(attempt)
+		}
+		lang.Close(rs.Body, "unable to close response body")
+		if attempt < client.options.Retries {
+			client.wait(rq, attempt, delay, rs.Status)
+		}
+	}
+	return nil, fmt.Errorf("%s: giving up after %d attempts: %w", client.name, client.options.Retries+1, lastErr)
+}
+
+func (client *Client) wait(rq *http.Request, attempt int, delay time.Duration, reason string) {
+	if attempt >= client.options.Retries {
+		return
+	}
+	log.Printf("%s: %s, retrying in %s (%d/%d)\n", client.name, reason, delay.Round(time.Millisecond), attempt+1, client.options.Retries)
+	select {
+	case <-time.After(delay):
+	case <-rq.Context().Done():
+	}
+}
+
+func (client *Client) throttle(rq *http.Request) error {
+	remaining := client.Remaining()
+	switch {
+	case remaining < 0:
+		return nil
+	case remaining <= client.options.MinRemaining:
+		return fmt.Errorf("%s: %w (%d remaining)", client.name, quota.ErrRateLimited, remaining)
+	case remaining < client.options.ThrottleBelow:
+		select {
+		case <-time.After(client.options.ThrottleDelay):
+		case <-rq.Context().Done():
+			return rq.Context().Err()
+		}
+	}
+	return nil
+}
+
+func (client *Client) updateRemaining(rs *http.Response) {
+	header := rs.Header.Get("x-ratelimit-remaining")
+	if header == "" {
+		header = rs.Header.Get("x-ratelimit-requests-remaining")
+	}
+	if remaining, err := strconv.Atoi(header); err == nil {
+		client.mutex.Lock()
+		client.remaining = remaining
+		client.mutex.Unlock()
+	}
+}
+
+// backoff doubles the delay with every attempt up to MaxBackoff and picks a random delay of at least half of it.
+func (client *Client) backoff(attempt int) time.Duration {
+	delay := client.options.MinBackoff << attempt
+	if delay > client.options.MaxBackoff || delay <= 0 {
+		delay = client.options.MaxBackoff
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// retryAfter parses the Retry-After header, given in seconds or as http date.
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second, seconds >= 0
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		if delay := date.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
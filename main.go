@@ -1,133 +1,169 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"flag"
 	"golang.org/x/text/language"
-	"kurse/color"
 	"kurse/exchangerates"
+	"kurse/history"
 	"kurse/lang"
+	"kurse/markets"
 	"kurse/portfolio"
+	"kurse/quota"
+	"kurse/quotes"
 	"kurse/yahoo"
+	"log"
 	"os"
-	"sort"
+	"os/signal"
 	"sync"
+	"time"
+)
+
+var (
+	showRisk = flag.Bool("risk", false, "show volatility, max drawdown, sharpe ratio and beta based on the snapshot history")
+	offline  = flag.Bool("offline", false, "use cached quotes and exchange rates regardless of their age without fetching")
+	strict   = flag.Bool("strict", false, "fail instead of valuing positions without quote or exchange rate at their cost basis")
+	timeout  = flag.Duration("timeout", 2*time.Minute, "overall deadline for fetching quotes and exchange rates")
 )
 
 func main() {
-	useCache := isUseCache()
+	flag.Parse()
 	out := NewOut(language.German)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
 
-	stocks, syms, secrets, err := portfolio.LoadPortfolio()
-	lang.FatalOnError(err)
-
-	results, rates := asyncFetch(secrets, syms, useCache)
-
-	symbols := make([]string, 0, len(stocks))
-	for symbol := range stocks {
-		symbols = append(symbols, string(symbol))
+	switch command := flag.Arg(0); command {
+	case "", "report":
+		v, settings := load(ctx)
+		printStaleBanner(out, v)
+		printReport(out, v.positions, v.totals, settings.Currency)
+		if *showRisk {
+			printRisk(out, inBaseCurrency(loadSnapshots(), rateHistory(), settings.Currency), settings.Risk)
+		}
+	case "snapshot":
+		v, _ := load(ctx)
+		printStaleBanner(out, v)
+		lang.FatalOnError(checkSnapshot(v))
+		snapshot := takeSnapshot(v)
+		snapshots, err := history.Record(snapshot)
+		lang.FatalOnError(err)
+		printSnapshot(out, snapshot, len(snapshots))
+	case "history":
+		snapshots := inBaseCurrency(loadSnapshots(), rateHistory(), reportingCurrency())
+		if flag.Arg(1) == "chart" {
+			printHistoryChart(out, snapshots)
+		} else {
+			printHistory(out, snapshots)
+		}
+	case "correlation":
+		days, err := correlationWindow(flag.Arg(1))
+		lang.FatalOnError(err)
+		printCorrelation(out, loadSnapshots(), days)
+	case "costs":
+		stocks, _, _, settings, err := portfolio.LoadPortfolio()
+		lang.FatalOnError(err)
+		lang.FatalOnError(printCosts(out, stocks, rateHistory(), settings.Currency))
+	case "quota":
+		_, _, _, settings, err := portfolio.LoadPortfolio()
+		lang.FatalOnError(err)
+		quota.Configure(settings.Quota)
+		ledger, err := quota.Load()
+		lang.FatalOnError(err)
+		printQuota(out, ledger, time.Now())
+	case "fx":
+		lang.FatalOnError(fx(ctx, out, flag.Args()[1:], reportingCurrency()))
+	default:
+		log.Fatalf("unknown command '%s', use one of: report, snapshot, history [chart], correlation [days], costs, fx, quota", command)
 	}
-	sort.Strings(symbols)
-	valSum := float64(0)
-	buySum := float64(0)
-	dividendSum := float64(0)
-	dividendSteuerSum := float64(0)
-	for _, symbol := range symbols {
-		stock := stocks[portfolio.Symbol(symbol)]
-		result, ok := results[symbol]
-		if ok {
-			var (
-				orderCount                    float64 = 0
-				orderPrice                    float64 = 0
-				orderProvision                float64 = 0
-				orderFee                      float64 = 0
-				orderBuy                      float64 = 0
-				dividendAmount                float64 = 0
-				dividendQuellensteuer         float64 = 0
-				dividendKapitalertragsteuer   float64 = 0
-				dividendSolidaritaetszuschlag float64 = 0
-				dividendKirchensteuer         float64 = 0
-			)
-
-			var rate = 1.0
-			currency, cok := rates.Data[result.Currency]
-			if cok {
-				rate = 1.0 / currency
-			}
+}
 
-			for _, order := range stock.Orders {
-				orderCount += order.Count
-				orderPrice += order.Price
-				orderProvision += order.Provision
-				orderFee += order.Fee
-				orderBuy += order.Price + order.Provision + order.Fee
-			}
-			for _, dividend := range stock.Dividends {
-				dividendAmount += dividend.Amount
-				dividendQuellensteuer += dividend.Quellensteuer
-				dividendKapitalertragsteuer += dividend.Kapitalertragsteuer
-				dividendSolidaritaetszuschlag += dividend.Solidaritaetszuschlag
-				dividendKirchensteuer += dividend.Kirchensteuer
-			}
+func load(ctx context.Context) (valuation, portfolio.Settings) {
+	useCache := isUseCache()
 
-			value := orderCount * result.RegularMarketPrice
-			eurValue := value * rate
-			guvV := eurValue + dividendAmount - orderBuy
-			if guvV >= 0 {
-				out.Print(color.GreenBackground, color.Black)
-			} else {
-				out.Print(color.RedBackground, color.Black)
-			}
-			var name string
-			if result.LongName == "" {
-				name = result.ShortName
-			} else {
-				name = fmt.Sprintf("%s (%s)", result.LongName, result.ShortName)
-			}
-			out.Printf("%s%s\n", name, color.Reset)
-			out.Printf("            Wert: %10.2f %s = %10.2f %s x %f\n", value, result.Currency, result.RegularMarketPrice, result.Currency, orderCount)
-			if rate != 1.0 {
-				out.Printf("               %10.2f EUR = %10.2f EUR x %f\n", eurValue, result.RegularMarketPrice*rate, orderCount)
-			}
-			out.Printf("            Kauf: %10.2f EUR (%.2fx%.2f=%.2f + %.2f + %.2f)\n", orderBuy, orderCount, orderPrice/orderCount, orderPrice, orderProvision, orderFee)
-			guvK := eurValue - orderBuy
-			guvKP := (eurValue / orderBuy * 100) - 100
-			out.Printf("             GuV: %s %s\n", color.ByAmount(guvK, "%+10.2f EUR"), color.ByAmount(guvKP, "(%+.2f%%)"))
-			dividendSteuer := dividendQuellensteuer + dividendKapitalertragsteuer + dividendSolidaritaetszuschlag + dividendKirchensteuer
-			out.Printf("       Dividende: %10.2f EUR (Brutto: %10.2f EUR | Steuer: %10.2f EUR)\n", dividendAmount, dividendAmount+dividendSteuer, dividendSteuer)
-			guvP := ((eurValue + dividendAmount) / orderBuy * 100) - 100
-			out.Printf("  GuV inkl. Div.: %s %s\n", color.ByAmount(guvV, "%+10.2f EUR"), color.ByAmount(guvP, "(%+.2f%%)"))
-			valSum += value * rate
-			buySum += orderBuy * rate
-			dividendSum += dividendAmount
-			dividendSteuerSum += dividendSteuer
-			out.Println()
+	stocks, _, secrets, settings, err := portfolio.LoadPortfolio()
+	lang.FatalOnError(err)
+	quota.Configure(settings.Quota)
+	settings.Http.Offline = *offline
+	if settings.Quotes.Provider == "" && len(settings.Quotes.Providers) == 0 {
+		settings.Quotes.Provider = yahoo.ProviderName
+	}
+	selection := make(map[portfolio.Symbol][]string, len(stocks)+1)
+	for symbol, stock := range stocks {
+		if stock.IsManual() {
+			selection[symbol] = []string{quotes.ManualProviderName}
+		} else {
+			selection[symbol] = settings.Quotes.ProvidersOf(stock)
 		}
 	}
+	if benchmark := settings.Risk.Benchmark; benchmark != "" {
+		if _, ok := selection[benchmark]; !ok {
+			selection[benchmark] = settings.Quotes.ProvidersOf(portfolio.Stock{Symbol: benchmark})
+		}
+	}
+	csvDirectory, err := settings.Quotes.CsvDirectory()
+	lang.FatalOnError(err)
+	providers := quotes.NewProviders(yahoo.NewProvider(secrets, settings.Quotes.Yahoo, settings.Http, settings.Cache.QuotesTTL(), useCache), quotes.NewCsv(csvDirectory), quotes.NewManual(stocks))
+	lang.FatalOnError(providers.Validate(selection))
+	rateProvider, err := exchangerates.NewProvider(settings.ExchangeRates.Provider, secrets, settings.Currency, settings.Http)
+	lang.FatalOnError(err)
 
-	out.Println("Summe:")
-	out.Printf("            Wert: %10.2f %s\n", valSum, "EUR")
-	out.Printf("            Kauf: %10.2f %s\n", buySum, "EUR")
-	out.Printf("             GuV: %s %s\n", color.ByAmount(valSum-buySum, "%+10.2f EUR"), color.ByAmount(valSum/buySum*100-100, "(%+.2f%%)"))
-	out.Printf("       Dividende: %10.2[1]f EUR (Brutto: %10.2[2]f EUR | Steuer: %10.2[3]f EUR)\n", dividendSum, dividendSum+dividendSteuerSum, dividendSteuerSum)
-	out.Printf("  GuV inkl. Div.: %s %s\n", color.ByAmount(valSum+dividendSum-buySum, "%+10.2f EUR"), color.ByAmount(((valSum+dividendSum)/buySum*100)-100, "(%+.2f%%)"))
-
+	fetched, rates := asyncFetch(ctx, providers, selection, rateProvider, settings.Cache.ExchangeRatesTTL(), useCache)
+	v, err := evaluate(stocks, fetched, rates, rateHistory(), settings)
+	lang.FatalOnError(err)
+	if unpriced := v.unpriced(); *strict && len(unpriced) > 0 {
+		log.Fatalf("%d positions without price in strict mode\n", len(unpriced))
+	}
+	return v, settings
 }
 
-func asyncFetch(secrets portfolio.Secrets, syms []portfolio.Symbol, cached bool) (yahoo.Results, exchangerates.Rates) {
+// asyncFetch fetches quotes and exchange rates concurrently until done or ctx is cancelled, failures are logged
+// and leave the respective data incomplete.
+func asyncFetch(ctx context.Context, providers quotes.Providers, selection map[portfolio.Symbol][]string, rateProvider exchangerates.Provider, ttl markets.TTL, cached bool) (quotes.Quotes, exchangerates.Rates) {
 	wg := sync.WaitGroup{}
 	wg.Add(2)
-	var results yahoo.Results
-	go func(results *yahoo.Results, wg *sync.WaitGroup) {
-		*results = yahoo.FetchStocks(syms, secrets, cached)
+	var fetched quotes.Quotes
+	go func(fetched *quotes.Quotes, wg *sync.WaitGroup) {
+		*fetched = providers.Fetch(ctx, selection)
 		wg.Done()
-	}(&results, &wg)
+	}(&fetched, &wg)
 	var rates exchangerates.Rates
 	go func(rates *exchangerates.Rates, wg *sync.WaitGroup) {
-		*rates = exchangerates.FetchExchangeRates(secrets, cached)
+		var err error
+		if *rates, err = exchangerates.FetchExchangeRates(ctx, rateProvider, ttl, cached); err != nil {
+			log.Printf("unable to fetch exchange rates: %v\n", err)
+		}
 		wg.Done()
 	}(&rates, &wg)
 	wg.Wait()
-	return results, rates
+	if err := ctx.Err(); err != nil {
+		log.Printf("fetching aborted, showing available data only: %v\n", err)
+	}
+	return fetched, rates
+}
+
+func loadSnapshots() history.Snapshots {
+	snapshots, err := history.Load()
+	lang.FatalOnError(err)
+	return snapshots
+}
+
+// rateHistory returns the stored exchange rates, without them orders in other currencies can't be converted.
+func rateHistory() *exchangerates.History {
+	fx, err := exchangerates.LoadHistory()
+	if err != nil {
+		log.Printf("%v, continuing without historical exchange rates\n", err)
+	}
+	return fx
+}
+
+// reportingCurrency returns the currency of the portfolio for commands that also work without a portfolio.
+func reportingCurrency() string {
+	if _, _, _, settings, err := portfolio.LoadPortfolio(); err == nil {
+		return settings.Currency
+	}
+	return portfolio.DefaultCurrency
 }
 
 func isUseCache() bool {
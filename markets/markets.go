@@ -0,0 +1,145 @@
+package markets
+
+import (
+	"time"
+	// embedded so trading hours also work on systems without timezone database, e.g. windows
+	_ "time/tzdata"
+)
+
+// Market describes the regular trading hours of an exchange, public holidays are not known and count as trading days.
+type Market struct {
+	Location   *time.Location
+	Open       time.Duration // since midnight
+	Close      time.Duration // since midnight
+	AlwaysOpen bool
+}
+
+// TTL configures how long cached data stays fresh: Open while the market is open, while it is closed until the
+// market opens again, but at most Closed if set.
+type TTL struct {
+	Open   time.Duration `yaml:"open" json:"open"`
+	Closed time.Duration `yaml:"closed" json:"closed"`
+}
+
+// WithDefault returns the TTL with open as the TTL while the market is open if none is configured.
+func (ttl TTL) WithDefault(open time.Duration) TTL {
+	if ttl.Open <= 0 {
+		ttl.Open = open
+	}
+	return ttl
+}
+
+type hours struct {
+	timezone    string
+	open, close time.Duration
+}
+
+// exchanges maps the exchange codes of yahoo to their trading hours.
+var exchanges = map[string]hours{
+	"NMS": {"America/New_York", clock(9, 30), clock(16, 0)},
+	"NGM": {"America/New_York", clock(9, 30), clock(16, 0)},
+	"NCM": {"America/New_York", clock(9, 30), clock(16, 0)},
+	"NYQ": {"America/New_York", clock(9, 30), clock(16, 0)},
+	"ASE": {"America/New_York", clock(9, 30), clock(16, 0)},
+	"PCX": {"America/New_York", clock(9, 30), clock(16, 0)},
+	"BTS": {"America/New_York", clock(9, 30), clock(16, 0)},
+	"TOR": {"America/Toronto", clock(9, 30), clock(16, 0)},
+	"GER": {"Europe/Berlin", clock(9, 0), clock(17, 30)},
+	"FRA": {"Europe/Berlin", clock(8, 0), clock(22, 0)},
+	"STU": {"Europe/Berlin", clock(8, 0), clock(22, 0)},
+	"MUN": {"Europe/Berlin", clock(8, 0), clock(22, 0)},
+	"BER": {"Europe/Berlin", clock(8, 0), clock(22, 0)},
+	"HAM": {"Europe/Berlin", clock(8, 0), clock(22, 0)},
+	"DUS": {"Europe/Berlin", clock(8, 0), clock(22, 0)},
+	"VIE": {"Europe/Vienna", clock(9, 0), clock(17, 30)},
+	"EBS": {"Europe/Zurich", clock(9, 0), clock(17, 30)},
+	"PAR": {"Europe/Paris", clock(9, 0), clock(17, 30)},
+	"AMS": {"Europe/Amsterdam", clock(9, 0), clock(17, 30)},
+	"BRU": {"Europe/Brussels", clock(9, 0), clock(17, 30)},
+	"MIL": {"Europe/Rome", clock(9, 0), clock(17, 30)},
+	"MCE": {"Europe/Madrid", clock(9, 0), clock(17, 30)},
+	"LSE": {"Europe/London", clock(8, 0), clock(16, 30)},
+	"TYO": {"Asia/Tokyo", clock(9, 0), clock(15, 0)},
+	"HKG": {"Asia/Hong_Kong", clock(9, 30), clock(16, 0)},
+}
+
+// defaultHours applies to exchanges not listed in exchanges but with a known timezone.
+var defaultHours = hours{open: clock(9, 0), close: clock(17, 30)}
+
+// Crypto trades around the clock.
+var Crypto = Market{Location: time.UTC, AlwaysOpen: true}
+
+// Forex is the currency market, it trades from monday to friday around the clock.
+var Forex = Market{Location: location("Europe/Berlin"), Open: 0, Close: 24 * time.Hour}
+
+// For returns the market of the yahoo exchange code, falling back to default trading hours in the timezone of the
+// exchange. Without a known timezone the market is considered always open.
+func For(exchange string, timezone string) Market {
+	if exchange == "CCC" {
+		return Crypto
+	}
+	h, ok := exchanges[exchange]
+	if !ok {
+		h = defaultHours
+		h.timezone = timezone
+	}
+	loc, err := time.LoadLocation(h.timezone)
+	if err != nil || h.timezone == "" {
+		return Market{Location: time.UTC, AlwaysOpen: true}
+	}
+	return Market{Location: loc, Open: h.open, Close: h.close}
+}
+
+// IsOpen reports whether the market trades at the given time.
+func (market Market) IsOpen(t time.Time) bool {
+	if market.AlwaysOpen {
+		return true
+	}
+	local := t.In(market.Location)
+	if !tradingDay(local) {
+		return false
+	}
+	since := clock(local.Hour(), local.Minute())
+	return since >= market.Open && since < market.Close
+}
+
+// NextOpen returns the next time the market opens after t.
+func (market Market) NextOpen(t time.Time) time.Time {
+	if market.AlwaysOpen {
+		return t
+	}
+	local := t.In(market.Location)
+	for day := 0; day <= 7; day++ {
+		date := local.AddDate(0, 0, day)
+		open := time.Date(date.Year(), date.Month(), date.Day(), 0, int(market.Open.Minutes()), 0, 0, market.Location)
+		if tradingDay(date) && open.After(t) {
+			return open
+		}
+	}
+	return t
+}
+
+// Expires returns until when data fetched at the given time stays fresh.
+func (market Market) Expires(fetched time.Time, ttl TTL) time.Time {
+	if market.IsOpen(fetched) {
+		return fetched.Add(ttl.Open)
+	}
+	next := market.NextOpen(fetched)
+	if ttl.Closed > 0 && fetched.Add(ttl.Closed).Before(next) {
+		return fetched.Add(ttl.Closed)
+	}
+	return next
+}
+
+func tradingDay(t time.Time) bool { return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday }
+
+func clock(hour, minute int) time.Duration {
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute
+}
+
+func location(name string) *time.Location {
+	if loc, err := time.LoadLocation(name); err == nil {
+		return loc
+	}
+	return time.UTC
+}
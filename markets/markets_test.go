@@ -0,0 +1,100 @@
+package markets
+
+import (
+	"testing"
+	"time"
+)
+
+func at(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestIsOpen(t *testing.T) {
+	tests := []struct {
+		name   string
+		market Market
+		at     string
+		want   bool
+	}{
+		{"xetra during trading hours", For("GER", ""), "2026-10-19T10:00:00+02:00", true},
+		{"xetra before opening", For("GER", ""), "2026-10-19T08:59:00+02:00", false},
+		{"xetra at closing", For("GER", ""), "2026-10-19T17:30:00+02:00", false},
+		{"xetra on saturday", For("GER", ""), "2026-10-17T10:00:00+02:00", false},
+		{"nasdaq in utc", For("NMS", "America/New_York"), "2026-10-19T14:00:00Z", true},
+		{"default hours of unknown exchange", For("SES", "Asia/Singapore"), "2026-10-19T18:00:00+08:00", false},
+		{"unknown exchange without timezone", For("XXX", ""), "2026-10-18T03:00:00Z", true},
+		{"crypto on sunday", For("CCC", "UTC"), "2026-10-18T03:00:00Z", true},
+		{"forex on friday night", Forex, "2026-10-16T23:30:00+02:00", true},
+		{"forex on saturday", Forex, "2026-10-17T12:00:00+02:00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.market.IsOpen(at(tt.at)); got != tt.want {
+				t.Errorf("IsOpen(%s) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextOpen(t *testing.T) {
+	tests := []struct {
+		name   string
+		market Market
+		at     string
+		want   string
+	}{
+		{"same day before opening", For("GER", ""), "2026-10-19T07:00:00+02:00", "2026-10-19T09:00:00+02:00"},
+		{"after closing", For("GER", ""), "2026-10-19T18:00:00+02:00", "2026-10-20T09:00:00+02:00"},
+		{"over the weekend", For("GER", ""), "2026-10-16T18:00:00+02:00", "2026-10-19T09:00:00+02:00"},
+		{"end of daylight saving time", For("GER", ""), "2026-10-23T18:00:00+02:00", "2026-10-26T09:00:00+01:00"},
+		{"start of daylight saving time", For("NYQ", ""), "2026-03-06T17:00:00-05:00", "2026-03-09T09:30:00-04:00"},
+		{"forex on saturday", Forex, "2026-10-17T12:00:00+02:00", "2026-10-19T00:00:00+02:00"},
+		{"crypto", Crypto, "2026-10-18T03:00:00Z", "2026-10-18T03:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.market.NextOpen(at(tt.at)); !got.Equal(at(tt.want)) {
+				t.Errorf("NextOpen(%s) = %s, want %s", tt.at, got.Format(time.RFC3339), tt.want)
+			}
+		})
+	}
+}
+
+func TestExpires(t *testing.T) {
+	ttl := TTL{Open: 15 * time.Minute}
+	capped := TTL{Open: 15 * time.Minute, Closed: 12 * time.Hour}
+	tests := []struct {
+		name    string
+		market  Market
+		ttl     TTL
+		fetched string
+		want    string
+	}{
+		{"open market", For("GER", ""), ttl, "2026-10-19T10:00:00+02:00", "2026-10-19T10:15:00+02:00"},
+		{"open shortly before closing", For("GER", ""), ttl, "2026-10-19T17:25:00+02:00", "2026-10-19T17:40:00+02:00"},
+		{"closed until next opening", For("GER", ""), ttl, "2026-10-16T18:00:00+02:00", "2026-10-19T09:00:00+02:00"},
+		{"closed capped", For("GER", ""), capped, "2026-10-16T18:00:00+02:00", "2026-10-17T06:00:00+02:00"},
+		{"cap beyond next opening", For("GER", ""), capped, "2026-10-19T07:00:00+02:00", "2026-10-19T09:00:00+02:00"},
+		{"crypto", Crypto, capped, "2026-10-18T03:00:00Z", "2026-10-18T03:15:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.market.Expires(at(tt.fetched), tt.ttl); !got.Equal(at(tt.want)) {
+				t.Errorf("Expires(%s) = %s, want %s", tt.fetched, got.Format(time.RFC3339), tt.want)
+			}
+		})
+	}
+}
+
+func TestWithDefault(t *testing.T) {
+	if got := (TTL{}).WithDefault(time.Minute); got.Open != time.Minute {
+		t.Errorf("WithDefault of empty TTL = %v, want %v", got.Open, time.Minute)
+	}
+	if got := (TTL{Open: time.Hour}).WithDefault(time.Minute); got.Open != time.Hour {
+		t.Errorf("WithDefault of configured TTL = %v, want %v", got.Open, time.Hour)
+	}
+}
@@ -0,0 +1,136 @@
+package money
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxPlaces limits the decimal places of String for values without a finite decimal representation, e.g. 1/3.
+const maxPlaces = 18
+
+// Decimal is an exact decimal number, the zero value is 0.
+type Decimal struct {
+	rat *big.Rat
+}
+
+var Zero = Decimal{}
+
+func NewFromInt(i int64) Decimal { return Decimal{rat: new(big.Rat).SetInt64(i)} }
+
+// NewFromFloat converts the shortest decimal representation of f, so 0.1 becomes exactly 0.1.
+func NewFromFloat(f float64) Decimal {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Zero
+	}
+	d, _ := Parse(strconv.FormatFloat(f, 'f', -1, 64))
+	return d
+}
+
+func Parse(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Zero, nil
+	}
+	if strings.Contains(s, "/") {
+		return Zero, fmt.Errorf("invalid decimal '%s'", s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Zero, fmt.Errorf("invalid decimal '%s'", s)
+	}
+	return Decimal{rat: r}, nil
+}
+
+func (d Decimal) r() *big.Rat {
+	if d.rat == nil {
+		return new(big.Rat)
+	}
+	return d.rat
+}
+
+func (d Decimal) Add(o Decimal) Decimal { return Decimal{rat: new(big.Rat).Add(d.r(), o.r())} }
+func (d Decimal) Sub(o Decimal) Decimal { return Decimal{rat: new(big.Rat).Sub(d.r(), o.r())} }
+func (d Decimal) Mul(o Decimal) Decimal { return Decimal{rat: new(big.Rat).Mul(d.r(), o.r())} }
+func (d Decimal) Neg() Decimal          { return Decimal{rat: new(big.Rat).Neg(d.r())} }
+func (d Decimal) Abs() Decimal          { return Decimal{rat: new(big.Rat).Abs(d.r())} }
+func (d Decimal) Cmp(o Decimal) int     { return d.r().Cmp(o.r()) }
+func (d Decimal) Sign() int             { return d.r().Sign() }
+func (d Decimal) IsZero() bool          { return d.Sign() == 0 }
+
+// Div divides exactly, it returns false when dividing by zero.
+func (d Decimal) Div(o Decimal) (Decimal, bool) {
+	if o.IsZero() {
+		return Zero, false
+	}
+	return Decimal{rat: new(big.Rat).Quo(d.r(), o.r())}, true
+}
+
+// Round rounds half away from zero to the given number of decimal places.
+func (d Decimal) Round(places int) Decimal {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	scaled := new(big.Rat).Mul(d.r(), new(big.Rat).SetInt(scale))
+	quotient, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	remainder.Abs(remainder).Mul(remainder, big.NewInt(2))
+	if remainder.Cmp(scaled.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(scaled.Sign())))
+	}
+	return Decimal{rat: new(big.Rat).SetFrac(quotient, scale)}
+}
+
+// Float64 returns the nearest float64, use it for ratios and output only.
+func (d Decimal) Float64() float64 {
+	f, _ := d.r().Float64()
+	return f
+}
+
+func (d Decimal) String() string {
+	r := d.r()
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	scaled := new(big.Rat).Set(r)
+	ten := new(big.Rat).SetInt64(10)
+	for places := 1; places <= maxPlaces; places++ {
+		scaled.Mul(scaled, ten)
+		if scaled.IsInt() {
+			return r.FloatString(places)
+		}
+	}
+	return strings.TrimRight(r.FloatString(maxPlaces), "0")
+}
+
+// StringFixed formats d rounded to the given number of decimal places.
+func (d Decimal) StringFixed(places int) string { return d.Round(places).r().FloatString(places) }
+
+func (d Decimal) MarshalJSON() ([]byte, error) { return []byte(d.String()), nil }
+
+func (d *Decimal) UnmarshalJSON(data []byte) (err error) {
+	if bytes.Equal(data, []byte("null")) {
+		*d = Zero
+		return nil
+	}
+	*d, err = Parse(string(bytes.Trim(data, `"`)))
+	return err
+}
+
+func (d *Decimal) UnmarshalYAML(unmarshal func(interface{}) error) (err error) {
+	var s string
+	if err = unmarshal(&s); err != nil {
+		return err
+	}
+	*d, err = Parse(s)
+	return err
+}
+
+// Percent returns d / of * 100 as float64 for output, it is NaN for of == 0.
+func Percent(d Decimal, of Decimal) float64 {
+	ratio, ok := d.Div(of)
+	if !ok {
+		return math.NaN()
+	}
+	return ratio.Float64() * 100
+}
@@ -0,0 +1,138 @@
+package money
+
+import (
+	"math"
+	"testing"
+)
+
+func parse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		value  string
+		places int
+		want   string
+	}{
+		{"1.005", 2, "1.01"},
+		{"1.004", 2, "1"},
+		{"-1.005", 2, "-1.01"},
+		{"-1.004", 2, "-1"},
+		{"2.5", 0, "3"},
+		{"-2.5", 0, "-3"},
+		{"0.125", 2, "0.13"},
+		{"123.456789", 4, "123.4568"},
+		{"0", 2, "0"},
+	}
+	for _, tt := range tests {
+		if got := parse(tt.value).Round(tt.places).String(); got != tt.want {
+			t.Errorf("%s.Round(%d) = %s, want %s", tt.value, tt.places, got, tt.want)
+		}
+	}
+	third, _ := NewFromInt(1).Div(NewFromInt(3))
+	if got := third.Round(4).String(); got != "0.3333" {
+		t.Errorf("1/3 rounded to 4 places = %s, want 0.3333", got)
+	}
+}
+
+func TestString(t *testing.T) {
+	third, _ := NewFromInt(1).Div(NewFromInt(3))
+	tests := []struct {
+		name   string
+		value  Decimal
+		places int
+		want   string
+		fixed  string
+	}{
+		{"integer", NewFromInt(42), 2, "42", "42.00"},
+		{"negative", parse("-0.5"), 2, "-0.5", "-0.50"},
+		{"trailing zeros", parse("1.2500"), 3, "1.25", "1.250"},
+		{"rounded", parse("2.675"), 2, "2.675", "2.68"},
+		{"no places", parse("2.5"), 0, "2.5", "3"},
+		{"zero value", Zero, 2, "0", "0.00"},
+		{"periodic", third, 4, "0.333333333333333333", "0.3333"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.value.String(); got != tt.want {
+				t.Errorf("String() = %s, want %s", got, tt.want)
+			}
+			if got := tt.value.StringFixed(tt.places); got != tt.fixed {
+				t.Errorf("StringFixed(%d) = %s, want %s", tt.places, got, tt.fixed)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+		err   bool
+	}{
+		{"1.23", "1.23", false},
+		{" -0.01 ", "-0.01", false},
+		{"1e3", "1000", false},
+		{"", "0", false},
+		{"1/3", "", true},
+		{"1,23", "", true},
+		{"abc", "", true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.value)
+		if tt.err {
+			if err == nil {
+				t.Errorf("Parse(%q) = %s, want error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil || got.String() != tt.want {
+			t.Errorf("Parse(%q) = %s, %v, want %s", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestNewFromFloat(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{0.1, "0.1"},
+		{-2.675, "-2.675"},
+		{1e-7, "0.0000001"},
+		{100, "100"},
+		{math.NaN(), "0"},
+		{math.Inf(1), "0"},
+	}
+	for _, tt := range tests {
+		if got := NewFromFloat(tt.value).String(); got != tt.want {
+			t.Errorf("NewFromFloat(%v) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyRound(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     string
+	}{
+		{"1.005", "EUR", "1.01 EUR"},
+		{"-1.005", "USD", "-1.01 USD"},
+		{"1234.5", "JPY", "1235 JPY"},
+		{"-1234.5", "KRW", "-1235 KRW"},
+		{"1.2345", "BHD", "1.235 BHD"},
+		{"1.2344", "KWD", "1.234 KWD"},
+		{"0.125", "XYZ", "0.13 XYZ"},
+	}
+	for _, tt := range tests {
+		if got := New(parse(tt.amount), tt.currency).Round().String(); got != tt.want {
+			t.Errorf("Round(%s %s) = %s, want %s", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
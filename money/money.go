@@ -0,0 +1,109 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// minorUnits lists the ISO 4217 currencies not using two decimal places.
+var minorUnits = map[string]int{
+	"BHD": 3, "CLP": 0, "IQD": 3, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0,
+	"KWD": 3, "LYD": 3, "OMR": 3, "PYG": 0, "TND": 3, "UGX": 0, "VND": 0,
+}
+
+// MinorUnits returns the number of decimal places of the currency.
+func MinorUnits(currency string) int {
+	if places, ok := minorUnits[currency]; ok {
+		return places
+	}
+	return 2
+}
+
+// subunits lists the currency codes exchanges use to quote prices in a subunit, e.g. pence at the London Stock
+// Exchange, with the ISO 4217 currency and the number of subunits per unit.
+var subunits = map[string]struct {
+	currency string
+	per      int64
+}{
+	"GBp": {"GBP", 100}, "GBX": {"GBP", 100},
+	"ZAc": {"ZAR", 100}, "ZAC": {"ZAR", 100},
+	"ILA": {"ILS", 100},
+}
+
+// Subunit returns the ISO 4217 currency and the number of subunits per unit for currency codes like GBp, ZAc or
+// ILA, any other currency is returned as is with 1.
+func Subunit(currency string) (string, int64) {
+	if unit, ok := subunits[currency]; ok {
+		return unit.currency, unit.per
+	}
+	return currency, 1
+}
+
+// Money is an amount in a currency. Amounts of different currencies can't be added without converting them.
+type Money struct {
+	Amount   Decimal `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+func New(amount Decimal, currency string) Money { return Money{Amount: amount, Currency: currency} }
+
+func Nothing(currency string) Money { return Money{Currency: currency} }
+
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return m, fmt.Errorf("%w: unable to add %s to %s", ErrCurrencyMismatch, o, m)
+	}
+	return New(m.Amount.Add(o.Amount), m.Currency), nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return m, fmt.Errorf("%w: unable to subtract %s from %s", ErrCurrencyMismatch, o, m)
+	}
+	return New(m.Amount.Sub(o.Amount), m.Currency), nil
+}
+
+// Sum adds all amounts, all of them must be in the given currency.
+func Sum(currency string, amounts ...Money) (Money, error) {
+	var err error
+	sum := Nothing(currency)
+	for _, amount := range amounts {
+		if sum, err = sum.Add(amount); err != nil {
+			return sum, err
+		}
+	}
+	return sum, nil
+}
+
+// Normalize converts an amount in a subunit like GBp into its ISO 4217 currency.
+func (m Money) Normalize() Money {
+	currency, per := Subunit(m.Currency)
+	if per == 1 {
+		return m
+	}
+	amount, _ := m.Amount.Div(NewFromInt(per))
+	return New(amount, currency)
+}
+
+func (m Money) Mul(factor Decimal) Money { return New(m.Amount.Mul(factor), m.Currency) }
+
+func (m Money) Neg() Money { return New(m.Amount.Neg(), m.Currency) }
+
+// Convert converts into the currency, rate is the amount of the target currency per unit of m's currency.
+func (m Money) Convert(rate Decimal, currency string) Money {
+	return New(m.Amount.Mul(rate), currency)
+}
+
+// Round rounds to the minor unit of the currency.
+func (m Money) Round() Money { return New(m.Amount.Round(MinorUnits(m.Currency)), m.Currency) }
+
+// Float64 returns the amount rounded to the minor unit of the currency, use it for output only.
+func (m Money) Float64() float64 { return m.Round().Amount.Float64() }
+
+func (m Money) Sign() int { return m.Amount.Sign() }
+
+func (m Money) String() string {
+	return fmt.Sprintf("%s %s", m.Amount.StringFixed(MinorUnits(m.Currency)), m.Currency)
+}
@@ -31,3 +31,6 @@ func (out *Out) Println(a ...any) {
 		fmt.Println(a...)
 	}
 }
+func (out *Out) Sprintf(format string, a ...any) string {
+	return out.printer.Sprintf(format, a...)
+}
@@ -1,6 +1,10 @@
 package portfolio
 
 import (
+	"kurse/httpclient"
+	"kurse/markets"
+	"kurse/money"
+	"kurse/quota"
 	"log"
 	"os"
 	"path"
@@ -10,34 +14,124 @@ import (
 )
 
 type Depot struct {
-	Stocks  []Stock `yaml:"stocks" json:"stocks"`
-	Secrets Secrets `yaml:"secrets" json:"secrets"`
+	Stocks   []Stock  `yaml:"stocks" json:"stocks"`
+	Secrets  Secrets  `yaml:"secrets" json:"secrets"`
+	Settings Settings `yaml:"settings" json:"settings"`
 }
 
 type Stock struct {
-	Symbol    Symbol     `yaml:"symbol" json:"symbol"`
-	Orders    []Order    `yaml:"orders" json:"orders"`
-	Dividends []Dividend `yaml:"dividends" json:"dividends"`
+	Symbol     Symbol      `yaml:"symbol" json:"symbol"`
+	Name       string      `yaml:"name" json:"name"`
+	Type       string      `yaml:"type" json:"type"`
+	Provider   string      `yaml:"provider" json:"provider"`
+	Broker     string      `yaml:"broker" json:"broker"`
+	Ter        float64     `yaml:"ter" json:"ter"`
+	Orders     []Order     `yaml:"orders" json:"orders"`
+	Dividends  []Dividend  `yaml:"dividends" json:"dividends"`
+	Valuations []Valuation `yaml:"valuations" json:"valuations"`
+	Bond       *Bond       `yaml:"bond" json:"bond"`
+}
+
+// TypeCrypto marks cryptocurrencies, gains of their lots are tax-free after a holding period of one year (§ 23 EStG).
+const TypeCrypto = "crypto"
+
+func (stock Stock) IsCrypto() bool { return stock.Type == TypeCrypto }
+
+// TaxFreeFrom returns the first day a sale of the order's units is tax-free, i.e. the day after the one-year
+// holding period ended. A period starting on 29 February ends on 28 February of the following year.
+func (order Order) TaxFreeFrom() time.Time {
+	d := order.Date
+	end := time.Date(d.Year()+1, d.Month(), 1, d.Hour(), d.Minute(), d.Second(), d.Nanosecond(), d.Location())
+	day := d.Day()
+	if last := end.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return end.AddDate(0, 0, day)
+}
+
+// TypeBond marks bonds, their count is the nominal value and their price is quoted in percent of it. Orders of
+// bonds carry the accrued interest (Stückzinsen) paid.
+const TypeBond = "bond"
+
+func (stock Stock) IsBond() bool { return stock.Type == TypeBond && stock.Bond != nil }
+
+type Bond struct {
+	Coupon    float64   `yaml:"coupon" json:"coupon"`       // in percent p.a.
+	Frequency int       `yaml:"frequency" json:"frequency"` // coupon payments per year
+	Maturity  time.Time `yaml:"maturity" json:"maturity"`
+}
+
+// Payments returns the coupon payments per year, supported are 1, 2, 4 and 12 with 1 as default.
+func (bond Bond) Payments() int {
+	switch bond.Frequency {
+	case 2, 4, 12:
+		return bond.Frequency
+	default:
+		return 1
+	}
+}
+
+// Valuation is a manually determined price per unit for stocks no provider quotes.
+type Valuation struct {
+	Date     time.Time     `yaml:"date" json:"date"`
+	Price    money.Decimal `yaml:"price" json:"price"`
+	Currency string        `yaml:"currency" json:"currency"`
+}
+
+// IsManual reports whether the stock is valued by manual valuations instead of a quote provider.
+func (stock Stock) IsManual() bool { return len(stock.Valuations) > 0 }
+
+// LatestValuation returns the most recent valuation not after the given time.
+func (stock Stock) LatestValuation(at time.Time) (Valuation, bool) {
+	var (
+		latest Valuation
+		found  bool
+	)
+	for _, valuation := range stock.Valuations {
+		if valuation.Date.After(at) {
+			continue
+		}
+		if !found || valuation.Date.After(latest.Date) {
+			latest = valuation
+			found = true
+		}
+	}
+	return latest, found
 }
 
 type Symbol string
 
+// DefaultCurrency is the reporting currency if none is configured.
+const DefaultCurrency = "EUR"
+
 type Order struct {
-	Date      time.Time `yaml:"date" json:"date"`
-	Count     float64   `yaml:"count" json:"count"`
-	Price     float64   `yaml:"price" json:"price"`
-	Provision float64   `yaml:"provision" json:"provision"`
-	Fee       float64   `yaml:"fee" json:"fee"`
+	Date            time.Time     `yaml:"date" json:"date"`
+	Count           money.Decimal `yaml:"count" json:"count"`
+	Price           money.Decimal `yaml:"price" json:"price"`
+	Provision       money.Decimal `yaml:"provision" json:"provision"`
+	Fee             money.Decimal `yaml:"fee" json:"fee"`
+	AccruedInterest money.Decimal `yaml:"accruedInterest" json:"accruedInterest"`
+	Currency        string        `yaml:"currency" json:"currency"`
+	Broker          string        `yaml:"broker" json:"broker"`
+}
+
+// BrokerOf returns the broker of the order, falling back to the broker of the stock.
+func (stock Stock) BrokerOf(order Order) string {
+	if order.Broker != "" {
+		return order.Broker
+	}
+	return stock.Broker
 }
 
 type Dividend struct {
-	Date                  time.Time `yaml:"date" json:"date"`
-	Count                 float64   `yaml:"count" json:"count"`
-	Amount                float64   `yaml:"amount" json:"amount"`
-	Quellensteuer         float64   `yaml:"quellensteuer" json:"quellensteuer"`
-	Kapitalertragsteuer   float64   `yaml:"kapitalertragsteuer" json:"kapitalertragsteuer"`
-	Solidaritaetszuschlag float64   `yaml:"solidaritaetszuschlag" json:"solidaritaetszuschlag"`
-	Kirchensteuer         float64   `yaml:"kirchensteuer" json:"kirchensteuer"`
+	Date                  time.Time     `yaml:"date" json:"date"`
+	Count                 money.Decimal `yaml:"count" json:"count"`
+	Amount                money.Decimal `yaml:"amount" json:"amount"`
+	Quellensteuer         money.Decimal `yaml:"quellensteuer" json:"quellensteuer"`
+	Kapitalertragsteuer   money.Decimal `yaml:"kapitalertragsteuer" json:"kapitalertragsteuer"`
+	Solidaritaetszuschlag money.Decimal `yaml:"solidaritaetszuschlag" json:"solidaritaetszuschlag"`
+	Kirchensteuer         money.Decimal `yaml:"kirchensteuer" json:"kirchensteuer"`
+	Currency              string        `yaml:"currency" json:"currency"`
 }
 
 type Secrets struct {
@@ -46,7 +140,118 @@ type Secrets struct {
 	FreecurrencyApiKey string `yaml:"freecurrencyApiKey" json:"freecurrencyApiKey"`
 }
 
-func LoadPortfolio() (map[Symbol]Stock, []Symbol, Secrets, error) {
+type Settings struct {
+	Currency      string                  `yaml:"currency" json:"currency"`
+	Quotes        Quotes                  `yaml:"quotes" json:"quotes"`
+	Bonds         Bonds                   `yaml:"bonds" json:"bonds"`
+	Http          httpclient.Options      `yaml:"http" json:"http"`
+	Quota         map[string]quota.Budget `yaml:"quota" json:"quota"`
+	ExchangeRates ExchangeRates           `yaml:"exchangeRates" json:"exchangeRates"`
+	Risk          Risk                    `yaml:"risk" json:"risk"`
+	Cache         Cache                   `yaml:"cache" json:"cache"`
+}
+
+// Cache configures how long cached quotes and exchange rates stay fresh depending on the trading hours.
+type Cache struct {
+	Quotes        markets.TTL `yaml:"quotes" json:"quotes"`
+	ExchangeRates markets.TTL `yaml:"exchangeRates" json:"exchangeRates"`
+}
+
+const (
+	defaultQuotesTTL        = 15 * time.Minute
+	defaultExchangeRatesTTL = 4 * time.Hour
+)
+
+// QuotesTTL returns the TTL of cached quotes, defaulting to 15 minutes while the market is open.
+func (cache Cache) QuotesTTL() markets.TTL { return cache.Quotes.WithDefault(defaultQuotesTTL) }
+
+// ExchangeRatesTTL returns the TTL of cached exchange rates, defaulting to 4 hours on weekdays.
+func (cache Cache) ExchangeRatesTTL() markets.TTL {
+	return cache.ExchangeRates.WithDefault(defaultExchangeRatesTTL)
+}
+
+const (
+	CleanPrice = "clean"
+	DirtyPrice = "dirty"
+)
+
+type Bonds struct {
+	// Valuation is either CleanPrice (default) or DirtyPrice, i.e. including accrued interest
+	Valuation string `yaml:"valuation" json:"valuation"`
+}
+
+func (bonds Bonds) Dirty() bool { return bonds.Valuation == DirtyPrice }
+
+type ExchangeRates struct {
+	Provider string `yaml:"provider" json:"provider"`
+}
+
+type Quotes struct {
+	Provider  string        `yaml:"provider" json:"provider"`
+	Providers []string      `yaml:"providers" json:"providers"`
+	Csv       Csv           `yaml:"csv" json:"csv"`
+	Yahoo     Yahoo         `yaml:"yahoo" json:"yahoo"`
+	MaxAge    time.Duration `yaml:"maxAge" json:"maxAge"`
+}
+
+// defaultMaxAge spans a weekend, so friday's closing prices are not reported as stale on monday morning.
+const defaultMaxAge = 72 * time.Hour
+
+// StaleAfter returns the age from which a quote is reported as stale, defaulting to 72 hours.
+func (quotes Quotes) StaleAfter() time.Duration {
+	if quotes.MaxAge > 0 {
+		return quotes.MaxAge
+	}
+	return defaultMaxAge
+}
+
+// Yahoo configures how many symbols are fetched per request and how many requests run at the same time.
+type Yahoo struct {
+	BatchSize   int `yaml:"batchSize" json:"batchSize"`
+	Concurrency int `yaml:"concurrency" json:"concurrency"`
+}
+
+type Csv struct {
+	Directory string `yaml:"directory" json:"directory"`
+}
+
+// CsvDirectory returns the configured directory of csv price files, defaulting to '{os.UserConfigDir()}/kurse/prices'.
+func (quotes Quotes) CsvDirectory() (string, error) {
+	if quotes.Csv.Directory != "" {
+		return quotes.Csv.Directory, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(dir, "kurse", "prices"), nil
+}
+
+// ProvidersOf returns the ordered quote providers to try for the stock: the provider of the stock followed by
+// the configured providers, or the configured default provider if no providers are configured.
+func (quotes Quotes) ProvidersOf(stock Stock) []string {
+	configured := quotes.Providers
+	if len(configured) == 0 && quotes.Provider != "" {
+		configured = []string{quotes.Provider}
+	}
+	providers := make([]string, 0, len(configured)+1)
+	if stock.Provider != "" {
+		providers = append(providers, stock.Provider)
+	}
+	for _, provider := range configured {
+		if provider != stock.Provider {
+			providers = append(providers, provider)
+		}
+	}
+	return providers
+}
+
+type Risk struct {
+	Benchmark    Symbol  `yaml:"benchmark" json:"benchmark"`
+	RiskFreeRate float64 `yaml:"riskFreeRate" json:"riskFreeRate"`
+}
+
+func LoadPortfolio() (map[Symbol]Stock, []Symbol, Secrets, Settings, error) {
 	var (
 		stocks   map[Symbol]Stock
 		symbols  []Symbol
@@ -55,24 +260,43 @@ func LoadPortfolio() (map[Symbol]Stock, []Symbol, Secrets, error) {
 		filename string
 	)
 	if filename, err = portfolioConfigurationFile(); err != nil {
-		return stocks, symbols, Secrets{}, err
+		return stocks, symbols, Secrets{}, Settings{}, err
 	}
 	log.Printf("loading portfolio from '%s'\n", filename)
 	if yml, err = os.ReadFile(filename); err != nil {
-		return stocks, symbols, Secrets{}, err
+		return stocks, symbols, Secrets{}, Settings{}, err
 	}
 	var depot = Depot{}
 	if err = yaml.Unmarshal(yml, &depot); err != nil {
-		return stocks, symbols, Secrets{}, err
+		return stocks, symbols, Secrets{}, Settings{}, err
 	}
+	if depot.Settings.Currency == "" {
+		depot.Settings.Currency = DefaultCurrency
+	}
+	currency := depot.Settings.Currency
 	stocks = make(map[Symbol]Stock)
 	symbols = make([]Symbol, 0, len(depot.Stocks))
 	for _, stock := range depot.Stocks {
+		for idx := range stock.Orders {
+			if stock.Orders[idx].Currency == "" {
+				stock.Orders[idx].Currency = currency
+			}
+		}
+		for idx := range stock.Dividends {
+			if stock.Dividends[idx].Currency == "" {
+				stock.Dividends[idx].Currency = currency
+			}
+		}
+		for idx := range stock.Valuations {
+			if stock.Valuations[idx].Currency == "" {
+				stock.Valuations[idx].Currency = currency
+			}
+		}
 		stocks[stock.Symbol] = stock
 		symbols = append(symbols, stock.Symbol)
 	}
 
-	return stocks, symbols, depot.Secrets, err
+	return stocks, symbols, depot.Secrets, depot.Settings, err
 }
 
 func portfolioConfigurationFile() (filename string, err error) {
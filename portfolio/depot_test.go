@@ -0,0 +1,27 @@
+package portfolio
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaxFreeFrom(t *testing.T) {
+	tests := []struct {
+		date string
+		want string
+	}{
+		{"2024-02-29", "2025-03-01"},
+		{"2023-02-28", "2024-02-29"},
+		{"2024-03-15", "2025-03-16"},
+		{"2024-12-31", "2026-01-01"},
+	}
+	for _, tt := range tests {
+		date, err := time.Parse("2006-01-02", tt.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := (Order{Date: date}).TaxFreeFrom().Format("2006-01-02"); got != tt.want {
+			t.Errorf("TaxFreeFrom(%s) = %s, want %s", tt.date, got, tt.want)
+		}
+	}
+}
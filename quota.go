@@ -0,0 +1,57 @@
+package main
+
+import (
+	"kurse/color"
+	"kurse/quota"
+	"sort"
+	"time"
+)
+
+func printQuota(out Out, ledger quota.Ledger, now time.Time) {
+	if len(ledger.Providers) == 0 {
+		out.Println("Noch keine Abrufe aufgezeichnet.")
+		return
+	}
+	month := now.Format("2006-01")
+	providers := make([]string, 0, len(ledger.Providers))
+	for provider := range ledger.Providers {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	for _, provider := range providers {
+		usage := ledger.Providers[provider]
+		out.Printf("%s:\n", provider)
+		calls := usage.Calls[month]
+		if budget, ok := quota.BudgetOf(provider); ok && budget.Calls > 0 {
+			left := budget.Calls - calls
+			line := color.InGreen
+			if left <= budget.Reserve {
+				line = color.InRed
+			} else if left <= 2*budget.Reserve {
+				line = color.InYellow
+			}
+			out.Printf("  Abrufe %s: %s\n", month, line(out.Sprintf("%d von %d (Reserve %d, verbleibend %d)", calls, budget.Calls, budget.Reserve, left)))
+		} else {
+			out.Printf("  Abrufe %s: %d (kein Budget konfiguriert)\n", month, calls)
+		}
+		if remaining, ok := usage.Remaining(); ok {
+			if limit, ok := usage.Limit(); ok {
+				out.Printf("  Laut Anbieter: %d von %d verbleibend (Stand %s)\n", remaining, limit, usage.LastCall.Format("2006-01-02 15:04"))
+			} else {
+				out.Printf("  Laut Anbieter: %d verbleibend (Stand %s)\n", remaining, usage.LastCall.Format("2006-01-02 15:04"))
+			}
+		} else if !usage.LastCall.IsZero() {
+			out.Printf("  Letzter Abruf: %s\n", usage.LastCall.Format("2006-01-02 15:04"))
+		}
+		months := make([]string, 0, len(usage.Calls))
+		for m := range usage.Calls {
+			if m != month {
+				months = append(months, m)
+			}
+		}
+		sort.Sort(sort.Reverse(sort.StringSlice(months)))
+		for _, m := range months {
+			out.Printf("  Abrufe %s: %d\n", m, usage.Calls[m])
+		}
+	}
+}
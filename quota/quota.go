@@ -0,0 +1,155 @@
+package quota
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kurse/stored"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const monthLayout = "2006-01"
+
+// ErrRateLimited is returned instead of calling a provider which reported that its remaining calls are used up.
+var ErrRateLimited = errors.New("rate limit reached")
+
+// ErrReserveReached is returned instead of calling a provider whose monthly budget is used up down to its reserve.
+var ErrReserveReached = errors.New("quota reserve reached")
+
+// Budget is the monthly number of calls of a provider, calls are refused once only Reserve calls are left.
+type Budget struct {
+	Calls   int `yaml:"calls" json:"calls"`
+	Reserve int `yaml:"reserve" json:"reserve"`
+}
+
+// Ledger persists the calls per provider and month and the rate limit headers of the last call across runs.
+type Ledger struct {
+	Providers map[string]*Usage `json:"providers"`
+}
+
+type Usage struct {
+	Calls     map[string]int    `json:"calls"`
+	RateLimit map[string]string `json:"rateLimit"`
+	LastCall  time.Time         `json:"lastCall"`
+}
+
+var (
+	mutex   sync.Mutex
+	budgets = map[string]Budget{}
+)
+
+// Configure sets the budgets per provider name.
+func Configure(providerBudgets map[string]Budget) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	budgets = providerBudgets
+}
+
+func BudgetOf(provider string) (Budget, bool) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	budget, ok := budgets[provider]
+	return budget, ok
+}
+
+func Load() (Ledger, error) {
+	ledger, ok, err := stored.Load("kurse", "quota.json", func(data []byte) (*Ledger, error) {
+		l := &Ledger{}
+		return l, json.Unmarshal(data, l)
+	})
+	if err != nil {
+		return Ledger{}, fmt.Errorf("unable to load quota ledger: %w", err)
+	}
+	if !ok || ledger.Providers == nil {
+		return Ledger{Providers: make(map[string]*Usage)}, nil
+	}
+	return *ledger, nil
+}
+
+func (ledger Ledger) save() error {
+	return stored.Save("kurse", "quota.json", &ledger, func(ledger *Ledger) ([]byte, error) {
+		return json.MarshalIndent(ledger, "", "  ")
+	})
+}
+
+// Check returns ErrReserveReached if the provider's budget or its reported remaining calls reached the reserve, or
+// the error reading the ledger, as the budget can't be checked then.
+func Check(provider string, now time.Time) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+	budget, ok := budgets[provider]
+	if !ok {
+		return nil
+	}
+	ledger, err := Load()
+	if err != nil {
+		return err
+	}
+	usage, ok := ledger.Providers[provider]
+	if !ok {
+		return nil
+	}
+	month := now.Format(monthLayout)
+	if budget.Calls > 0 && budget.Calls-usage.Calls[month] <= budget.Reserve {
+		return fmt.Errorf("%s: %w (%d of %d calls in %s)", provider, ErrReserveReached, usage.Calls[month], budget.Calls, month)
+	}
+	if remaining, ok := usage.Remaining(); ok && usage.LastCall.Format(monthLayout) == month && remaining <= budget.Reserve {
+		return fmt.Errorf("%s: %w (%d calls remaining)", provider, ErrReserveReached, remaining)
+	}
+	return nil
+}
+
+// Record counts a call of the provider and keeps its rate limit headers, an unreadable ledger is left untouched.
+func Record(provider string, header http.Header, now time.Time) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+	ledger, err := Load()
+	if err != nil {
+		return err
+	}
+	usage, ok := ledger.Providers[provider]
+	if !ok {
+		usage = &Usage{}
+		ledger.Providers[provider] = usage
+	}
+	if usage.Calls == nil {
+		usage.Calls = make(map[string]int)
+	}
+	usage.Calls[now.Format(monthLayout)]++
+	usage.LastCall = now
+	rateLimit := make(map[string]string)
+	for name := range header {
+		if strings.HasPrefix(strings.ToLower(name), "x-ratelimit") {
+			rateLimit[strings.ToLower(name)] = header.Get(name)
+		}
+	}
+	if len(rateLimit) > 0 {
+		usage.RateLimit = rateLimit
+	}
+	return ledger.save()
+}
+
+// Remaining returns the remaining calls of the last rate limit headers.
+func (usage Usage) Remaining() (int, bool) {
+	return usage.header("remaining")
+}
+
+// Limit returns the call limit of the last rate limit headers.
+func (usage Usage) Limit() (int, bool) {
+	return usage.header("limit")
+}
+
+func (usage Usage) header(suffix string) (int, bool) {
+	for _, name := range []string{"x-ratelimit-requests-" + suffix, "x-ratelimit-" + suffix} {
+		if value, ok := usage.RateLimit[name]; ok {
+			if i, err := strconv.Atoi(value); err == nil {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
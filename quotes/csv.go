@@ -0,0 +1,121 @@
+package quotes
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"kurse/lang"
+	"kurse/money"
+	"kurse/portfolio"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	CsvProviderName = "csv"
+	csvDateLayout   = "2006-01-02"
+)
+
+// Csv delivers the latest prices found in the csv files (symbol, date, price, currency) of a directory.
+// Files may use ',' or ';' as separator, the latter with decimal comma, a header line is skipped.
+type Csv struct {
+	directory string
+}
+
+func NewCsv(directory string) *Csv { return &Csv{directory: directory} }
+
+func (provider *Csv) Name() string { return CsvProviderName }
+
+func (provider *Csv) FetchQuotes(_ context.Context, symbols []portfolio.Symbol) (Quotes, error) {
+	files, err := filepath.Glob(filepath.Join(provider.directory, "*.csv"))
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[portfolio.Symbol]bool, len(symbols))
+	for _, symbol := range symbols {
+		wanted[symbol] = true
+	}
+	quotes := make(Quotes, len(symbols))
+	for _, file := range files {
+		if err = readCsvPrices(file, wanted, quotes); err != nil {
+			log.Printf("skipping %s: %v\n", file, err)
+		}
+	}
+	return quotes, nil
+}
+
+// readCsvPrices adds the prices of the wanted symbols in the file to quotes, malformed lines are logged and skipped.
+func readCsvPrices(file string, wanted map[portfolio.Symbol]bool, quotes Quotes) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer lang.Close(f, "unable to close file")
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	semicolon := strings.Count(string(data), ";") > strings.Count(string(data), ",")
+	if semicolon {
+		reader.Comma = ';'
+	}
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			log.Printf("skipping %s:%d: %v\n", file, parseErr.StartLine, parseErr.Err)
+			continue
+		} else if err != nil {
+			return err
+		}
+		line, _ := reader.FieldPos(0)
+		if err = readCsvPrice(record, semicolon, wanted, quotes); err != nil && !first {
+			log.Printf("skipping %s:%d: %v\n", file, line, err)
+		}
+	}
+}
+
+// readCsvPrice adds the price of the record to quotes if its symbol is wanted and it is newer than the known price.
+func readCsvPrice(record []string, semicolon bool, wanted map[portfolio.Symbol]bool, quotes Quotes) error {
+	if len(record) < 4 {
+		return errors.New("expected symbol, date, price, currency")
+	}
+	date, err := time.Parse(csvDateLayout, strings.TrimSpace(record[1]))
+	if err != nil {
+		return err
+	}
+	symbol := portfolio.Symbol(strings.TrimSpace(record[0]))
+	if !wanted[symbol] {
+		return nil
+	}
+	if quote, ok := quotes[symbol]; ok && !date.After(quote.Time) {
+		return nil
+	}
+	price := strings.TrimSpace(record[2])
+	if semicolon {
+		price = strings.ReplaceAll(strings.ReplaceAll(price, ".", ""), ",", ".")
+	}
+	amount, err := money.Parse(price)
+	if err != nil {
+		return err
+	}
+	quotes[symbol] = Quote{
+		Symbol:   symbol,
+		Name:     string(symbol),
+		Currency: strings.TrimSpace(record[3]),
+		Price:    amount,
+		Time:     date,
+		Provider: CsvProviderName,
+	}
+	return nil
+}
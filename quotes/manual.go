@@ -0,0 +1,52 @@
+package quotes
+
+import (
+	"context"
+	"kurse/portfolio"
+	"time"
+)
+
+const (
+	ManualProviderName = "manual"
+	MarketStateManual  = "MANUAL"
+)
+
+// Manual delivers the latest manual valuation of the portfolio as quote.
+type Manual struct {
+	stocks map[portfolio.Symbol]portfolio.Stock
+}
+
+func NewManual(stocks map[portfolio.Symbol]portfolio.Stock) *Manual {
+	return &Manual{stocks: stocks}
+}
+
+func (manual *Manual) Name() string { return ManualProviderName }
+
+func (manual *Manual) FetchQuotes(_ context.Context, symbols []portfolio.Symbol) (Quotes, error) {
+	now := time.Now()
+	quotes := make(Quotes, len(symbols))
+	for _, symbol := range symbols {
+		stock, ok := manual.stocks[symbol]
+		if !ok {
+			continue
+		}
+		valuation, ok := stock.LatestValuation(now)
+		if !ok {
+			continue
+		}
+		name := stock.Name
+		if name == "" {
+			name = string(symbol)
+		}
+		quotes[symbol] = Quote{
+			Symbol:      symbol,
+			Name:        name,
+			Currency:    valuation.Currency,
+			Price:       valuation.Price,
+			MarketState: MarketStateManual,
+			Time:        valuation.Date,
+			Provider:    ManualProviderName,
+		}
+	}
+	return quotes, nil
+}
@@ -0,0 +1,135 @@
+package quotes
+
+import (
+	"context"
+	"fmt"
+	"kurse/money"
+	"kurse/portfolio"
+	"log"
+	"sort"
+	"time"
+)
+
+// Quote is the provider independent price information of a symbol.
+type Quote struct {
+	Symbol        portfolio.Symbol `json:"symbol"`
+	Name          string           `json:"name"`
+	Currency      string           `json:"currency"`
+	Price         money.Decimal    `json:"price"`
+	Change        money.Decimal    `json:"change"`
+	ChangePercent float64          `json:"changePercent"`
+	MarketState   string           `json:"marketState"`
+	Time          time.Time        `json:"time"`
+	Exchange      string           `json:"exchange"`
+	Timezone      string           `json:"timezone"`
+	Delay         time.Duration    `json:"delay"`
+	Provider      string           `json:"provider"`
+	Extended      *ExtendedHours   `json:"extended,omitempty"`
+	// Stale is the time an outdated quote was fetched, if it had to be taken from a cache because fetching failed
+	Stale time.Time `json:"stale"`
+}
+
+const (
+	PreMarket  = "pre"
+	PostMarket = "post"
+)
+
+// ExtendedHours is a price outside of the regular trading hours, Session is either PreMarket or PostMarket. Change
+// is relative to the previous close in case of PreMarket and to the regular close in case of PostMarket.
+type ExtendedHours struct {
+	Session       string        `json:"session"`
+	Price         money.Decimal `json:"price"`
+	Change        money.Decimal `json:"change"`
+	ChangePercent float64       `json:"changePercent"`
+	Time          time.Time     `json:"time"`
+}
+
+func (quote Quote) Money() money.Money { return money.New(quote.Price, quote.Currency) }
+
+// Normalize converts the prices of a quote in a subunit like GBp (pence) or ZAc (cents) into its ISO 4217 currency.
+func (quote Quote) Normalize() Quote {
+	currency, per := money.Subunit(quote.Currency)
+	if per == 1 {
+		return quote
+	}
+	quote.Price = money.New(quote.Price, quote.Currency).Normalize().Amount
+	quote.Change = money.New(quote.Change, quote.Currency).Normalize().Amount
+	if quote.Extended != nil {
+		extended := *quote.Extended
+		extended.Price = money.New(extended.Price, quote.Currency).Normalize().Amount
+		extended.Change = money.New(extended.Change, quote.Currency).Normalize().Amount
+		quote.Extended = &extended
+	}
+	quote.Currency = currency
+	return quote
+}
+
+type Quotes map[portfolio.Symbol]Quote
+
+// Provider delivers quotes for symbols, symbols unknown to the provider are missing in the result.
+type Provider interface {
+	Name() string
+	FetchQuotes(ctx context.Context, symbols []portfolio.Symbol) (Quotes, error)
+}
+
+type Providers map[string]Provider
+
+func NewProviders(providers ...Provider) Providers {
+	registry := make(Providers, len(providers))
+	for _, provider := range providers {
+		registry[provider.Name()] = provider
+	}
+	return registry
+}
+
+// Validate checks that all providers of the selection are known.
+func (providers Providers) Validate(selection map[portfolio.Symbol][]string) error {
+	for symbol, names := range selection {
+		for _, name := range names {
+			if _, ok := providers[name]; !ok {
+				return fmt.Errorf("unknown quote provider '%s' for %s", name, symbol)
+			}
+		}
+	}
+	return nil
+}
+
+// Fetch fetches every symbol from the ordered providers selected for it. Symbols a provider fails for or doesn't
+// deliver are retried with the next provider of the symbol. Symbols no provider delivered are missing in the result,
+// when the context is done the quotes fetched so far are returned.
+func (providers Providers) Fetch(ctx context.Context, selection map[portfolio.Symbol][]string) Quotes {
+	quotes := make(Quotes, len(selection))
+	for round := 0; ctx.Err() == nil; round++ {
+		bySource := make(map[string][]portfolio.Symbol)
+		for symbol, names := range selection {
+			if _, ok := quotes[symbol]; !ok && round < len(names) {
+				bySource[names[round]] = append(bySource[names[round]], symbol)
+			}
+		}
+		if len(bySource) == 0 {
+			return quotes
+		}
+		for name, symbols := range bySource {
+			provider, ok := providers[name]
+			if !ok {
+				log.Printf("unknown quote provider '%s' for %v\n", name, symbols)
+				continue
+			}
+			sort.Slice(symbols, func(i, j int) bool { return symbols[i] < symbols[j] })
+			fetched, err := provider.FetchQuotes(ctx, symbols)
+			if err != nil {
+				log.Printf("unable to fetch quotes from '%s': %v\n", name, err)
+				continue
+			}
+			for _, symbol := range symbols {
+				if quote, ok := fetched[symbol]; ok {
+					quote.Provider = name
+					quotes[symbol] = quote.Normalize()
+				} else if round+1 < len(selection[symbol]) {
+					log.Printf("'%s' delivered no quote for %s, trying '%s'\n", name, symbol, selection[symbol][round+1])
+				}
+			}
+		}
+	}
+	return quotes
+}
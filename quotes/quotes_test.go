@@ -0,0 +1,52 @@
+package quotes
+
+import (
+	"kurse/money"
+	"testing"
+)
+
+func decimal(s string) money.Decimal {
+	d, err := money.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		currency     string
+		price        string
+		change       string
+		wantPrice    string
+		wantCurrency string
+		wantChange   string
+	}{
+		{"GBp", "1234.5", "-12", "12.345", "GBP", "-0.12"},
+		{"GBX", "98", "0.5", "0.98", "GBP", "0.005"},
+		{"ZAc", "15000", "250", "150", "ZAR", "2.5"},
+		{"ILA", "2500", "0", "25", "ILS", "0"},
+		{"GBP", "12.34", "0.1", "12.34", "GBP", "0.1"},
+		{"EUR", "100", "-1", "100", "EUR", "-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			quote := Quote{
+				Currency: tt.currency,
+				Price:    decimal(tt.price),
+				Change:   decimal(tt.change),
+				Extended: &ExtendedHours{Price: decimal(tt.price), Change: decimal(tt.change)},
+			}
+			got := quote.Normalize()
+			if got.Currency != tt.wantCurrency || got.Price.String() != tt.wantPrice || got.Change.String() != tt.wantChange {
+				t.Errorf("Normalize() = %s %s (%s), want %s %s (%s)", got.Price, got.Currency, got.Change, tt.wantPrice, tt.wantCurrency, tt.wantChange)
+			}
+			if got.Extended.Price.String() != tt.wantPrice || got.Extended.Change.String() != tt.wantChange {
+				t.Errorf("extended hours = %s (%s), want %s (%s)", got.Extended.Price, got.Extended.Change, tt.wantPrice, tt.wantChange)
+			}
+			if quote.Price.String() != decimal(tt.price).String() || quote.Extended.Price.String() != decimal(tt.price).String() {
+				t.Error("Normalize modified the original quote")
+			}
+		})
+	}
+}
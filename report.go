@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"kurse/color"
+	"kurse/money"
+	"kurse/quotes"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxUpcomingCoupons = 4
+
+func printReport(out Out, positions []position, sums totals, currency string) {
+	for _, p := range positions {
+		if p.guvInklDividend.Sign() >= 0 {
+			out.Print(color.GreenBackground, color.Black)
+		} else {
+			out.Print(color.RedBackground, color.Black)
+		}
+		if !p.priced() {
+			out.Printf("%s%s %s\n", p.name, color.Reset, color.InRed("["+p.unpriced+", bewertet zum Kaufpreis]"))
+		} else if p.manual() {
+			out.Printf("%s%s %s\n", p.name, color.Reset, color.InYellow("[manuell bewertet am "+p.quoteTime.Format(dateLayout)+"]"))
+		} else {
+			out.Printf("%s%s %s\n", p.name, color.Reset, color.InGray("["+providerLabel(p)+"]"))
+		}
+		if !p.priced() {
+			label := "            Wert:"
+			if p.price.Sign() != 0 && p.bond != nil {
+				printBondValue(out, p, currency)
+				label = "                 "
+			} else if p.price.Sign() != 0 {
+				out.Printf("%s %10.2f %s = %10.2f %s x %f\n", label, p.value.Float64(), p.currency, p.price.Amount.Float64(), p.currency, p.orderCount.Float64())
+				label = "                 "
+			}
+			out.Printf("%s %10.2f %s (Kaufpreis)\n", label, p.baseValue.Float64(), currency)
+		} else if p.bond != nil {
+			printBondValue(out, p, currency)
+		} else {
+			out.Printf("            Wert: %10.2f %s = %10.2f %s x %f\n", p.value.Float64(), p.currency, p.price.Amount.Float64(), p.currency, p.orderCount.Float64())
+		}
+		if p.converted() && p.bond == nil {
+			out.Printf("               %10.2f %s = %10.2f %s x %f\n", p.baseValue.Float64(), currency, p.price.Convert(p.rate, currency).Amount.Float64(), currency, p.orderCount.Float64())
+		}
+		if p.extended != nil && p.priced() {
+			printExtendedHours(out, p)
+		}
+		if p.priced() && !p.manual() && !p.quoteTime.IsZero() {
+			printQuoteTime(out, p, time.Now())
+		}
+		orderAvgPrice, _ := p.orderPrice.Amount.Div(p.orderCount)
+		if p.bond != nil {
+			orderAvgPrice = orderAvgPrice.Mul(money.NewFromInt(100))
+		}
+		if p.bond != nil && p.bond.dirty {
+			out.Printf("            Kauf: %10.2f %s (%.2fx%.2f%%=%.2f + %.2f + %.2f + %.2f Stückzinsen)\n", p.orderBuy.Float64(), currency, p.orderCount.Float64(), orderAvgPrice.Float64(), p.orderPrice.Float64(), p.orderProvision.Float64(), p.orderFee.Float64(), p.bond.paidAccruedInterest.Float64())
+		} else {
+			out.Printf("            Kauf: %10.2f %s (%.2fx%.2f=%.2f + %.2f + %.2f)\n", p.orderBuy.Float64(), currency, p.orderCount.Float64(), orderAvgPrice.Float64(), p.orderPrice.Float64(), p.orderProvision.Float64(), p.orderFee.Float64())
+		}
+		printGuv(out, "             GuV:", p.guv, p.orderBuy)
+		out.Printf("       Dividende: %10.2[1]f %[4]s (Brutto: %10.2[2]f %[4]s | Steuer: %10.2[3]f %[4]s)\n", p.dividendAmount.Float64(), p.dividendBrutto.Float64(), p.dividendSteuer.Float64(), currency)
+		printGuv(out, "  GuV inkl. Div.:", p.guvInklDividend, p.orderBuy)
+		if len(p.lots) > 0 {
+			printLots(out, p, currency, time.Now())
+		}
+		if p.bond != nil {
+			printBond(out, p)
+		}
+		out.Println()
+	}
+
+	printUnpriced(out, positions)
+	out.Println("Summe:")
+	out.Printf("            Wert: %10.2f %s\n", sums.value.Float64(), currency)
+	if sums.manualValue.Sign() != 0 {
+		out.Printf("                  %sdavon %.2f %s manuell bewertet%s\n", color.Yellow, sums.manualValue.Float64(), currency, color.Reset)
+	}
+	if sums.unpricedValue.Sign() != 0 {
+		out.Printf("                  %sdavon %.2f %s ohne Kurs zum Kaufpreis%s\n", color.Red, sums.unpricedValue.Float64(), currency, color.Reset)
+	}
+	out.Printf("            Kauf: %10.2f %s\n", sums.buy.Float64(), currency)
+	printGuv(out, "             GuV:", sums.guv, sums.buy)
+	out.Printf("       Dividende: %10.2[1]f %[4]s (Brutto: %10.2[2]f %[4]s | Steuer: %10.2[3]f %[4]s)\n", sums.dividend.Float64(), sums.dividendBrutto.Float64(), sums.dividendSteuer.Float64(), currency)
+	printGuv(out, "  GuV inkl. Div.:", sums.guvInklDividend, sums.buy)
+}
+
+// printStaleBanner warns about quotes and exchange rates taken from an outdated cache because fetching them failed.
+func printStaleBanner(out Out, v valuation) {
+	var (
+		since   time.Time
+		symbols []string
+	)
+	for _, p := range v.positions {
+		if p.cachedSince.IsZero() {
+			continue
+		}
+		symbols = append(symbols, string(p.symbol))
+		if since.IsZero() || p.cachedSince.Before(since) {
+			since = p.cachedSince
+		}
+	}
+	if len(symbols) > 0 {
+		out.Printf("%s%s Kurse veraltet seit %s: %s %s\n", color.RedBackground, color.Black, since.Format("2006-01-02 15:04"), strings.Join(symbols, ", "), color.Reset)
+	}
+	if !v.ratesCachedSince.IsZero() {
+		out.Printf("%s%s Umrechnungskurse veraltet seit %s %s\n", color.RedBackground, color.Black, v.ratesCachedSince.Format("2006-01-02 15:04"), color.Reset)
+	}
+	if len(symbols) > 0 || !v.ratesCachedSince.IsZero() {
+		out.Println()
+	}
+}
+
+// marketStates labels the market states reported by yahoo.
+var marketStates = map[string]string{
+	"PREPRE":   "vorbörslich",
+	"PRE":      "vorbörslich",
+	"REGULAR":  "Handel",
+	"POST":     "nachbörslich",
+	"POSTPOST": "nachbörslich",
+	"CLOSED":   "geschlossen",
+}
+
+func providerLabel(p position) string {
+	if state, ok := marketStates[p.marketState]; ok {
+		return p.provider + ", " + state
+	}
+	return p.provider
+}
+
+// printExtendedHours shows the pre- or post-market price and its change, the value is based on the regular price.
+func printExtendedHours(out Out, p position) {
+	label := "    Nachbörslich:"
+	if p.extended.Session == quotes.PreMarket {
+		label = "     Vorbörslich:"
+	}
+	out.Printf("%s %10.2f %s %s %s %s\n", label, p.extended.Price.Float64(), p.currency,
+		color.ByAmount(p.extended.Change.Float64(), "%+.2f "+p.currency), color.ByAmount(p.extended.ChangePercent, "(%+.2f%%)"),
+		color.InGray("um "+p.extended.Time.Format("2006-01-02 15:04")))
+}
+
+// printQuoteTime shows when the price was determined in the timezone of the exchange and the delay of its data.
+func printQuoteTime(out Out, p position, now time.Time) {
+	at := p.quoteTime
+	if location, err := time.LoadLocation(p.timezone); err == nil && p.timezone != "" {
+		at = at.In(location)
+	}
+	stand := at.Format("2006-01-02 15:04 MST")
+	if p.timezone == "" && at.Hour() == 0 && at.Minute() == 0 {
+		stand = at.Format(dateLayout)
+	}
+	if p.delay > 0 {
+		stand += fmt.Sprintf(", %d Min. verzögert", int(p.delay.Minutes()))
+	}
+	if p.stale {
+		out.Printf("           Stand: %s %s\n", stand, color.InYellow("[veraltet, "+age(now.Sub(p.quoteTime))+" alt]"))
+	} else {
+		out.Printf("           Stand: %s\n", color.InGray(stand))
+	}
+}
+
+func age(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return strconv.Itoa(int(d.Hours()/24)) + " Tage"
+	case d >= 2*time.Hour:
+		return strconv.Itoa(int(d.Hours())) + " Stunden"
+	default:
+		return strconv.Itoa(int(d.Minutes())) + " Minuten"
+	}
+}
+
+// printUnpriced lists the positions without a quote or exchange rate, they are included at their cost basis.
+func printUnpriced(out Out, positions []position) {
+	header := false
+	for _, p := range positions {
+		if p.priced() {
+			continue
+		}
+		if !header {
+			out.Println(color.InRed("Ohne Kurs:"))
+			header = true
+		}
+		out.Printf("  %-16s %s\n", p.symbol, p.unpriced)
+	}
+	if header {
+		out.Println()
+	}
+}
+
+func printLots(out Out, p position, currency string, now time.Time) {
+	out.Println("            Lose:")
+	for _, l := range p.lots {
+		out.Printf("      %s %14.8f Kauf: %10.2f %s  Wert: %10.2f %s  GuV: %s  ", l.date.Format(dateLayout), l.count.Float64(), l.buy.Float64(), currency, l.value.Float64(), currency, color.ByAmount(l.guv.Float64(), "%+10.2f "+currency))
+		if l.taxFree(now) {
+			out.Printf("%s\n", color.InGreen("steuerfrei seit "+l.taxFreeFrom.Format(dateLayout)))
+		} else {
+			days := int(math.Ceil(l.taxFreeFrom.Sub(now).Hours() / 24))
+			out.Printf("%s\n", color.InYellow(fmt.Sprintf("steuerfrei ab %s (in %d Tagen)", l.taxFreeFrom.Format(dateLayout), days)))
+		}
+	}
+	out.Printf("  GuV steuerfrei: %s\n", color.ByAmount(p.taxFreeGuv.Float64(), "%+10.2f "+currency))
+	out.Printf("  GuV steuerpfl.: %s\n", color.ByAmount(p.taxableGuv.Float64(), "%+10.2f "+currency))
+}
+
+func printBondValue(out Out, p position, currency string) {
+	b := p.bond
+	if b.dirty {
+		out.Printf("            Wert: %10.2f %s = %.3f%% x %.2f %s + %.2f %s Stückzinsen (dirty)\n", p.value.Float64(), p.currency, p.price.Amount.Float64(), p.orderCount.Float64(), p.currency, b.accruedInterest.Float64(), p.currency)
+	} else {
+		out.Printf("            Wert: %10.2f %s = %.3f%% x %.2f %s (clean)\n", p.value.Float64(), p.currency, p.price.Amount.Float64(), p.orderCount.Float64(), p.currency)
+	}
+	if p.converted() {
+		out.Printf("                  %10.2f %s\n", p.baseValue.Float64(), currency)
+	}
+}
+
+func printBond(out Out, p position) {
+	b := p.bond
+	out.Printf("         Anleihe: %.3f%% Kupon, %d x jährlich, fällig am %s\n", b.Coupon, b.Payments(), b.Maturity.Format(dateLayout))
+	out.Printf("     Stückzinsen: %10.2f %s\n", b.accruedInterest.Float64(), b.accruedInterest.Currency)
+	if b.yieldKnown {
+		out.Printf("   Rendite (YTM): %+10.3f%%\n", b.yield*100)
+	} else {
+		out.Printf("   Rendite (YTM): %10s\n", "-")
+	}
+	for idx, c := range b.coupons {
+		if idx == maxUpcomingCoupons {
+			out.Printf("                  ... %d weitere Kupons bis %s\n", len(b.coupons)-idx, b.Maturity.Format(dateLayout))
+			break
+		}
+		label := "                 "
+		if idx == 0 {
+			label = "  Nächste Kupons:"
+		}
+		out.Printf("%s %s %10.2f %s\n", label, c.date.Format(dateLayout), c.amount.Float64(), c.amount.Currency)
+	}
+}
+
+func printGuv(out Out, label string, guv money.Money, buy money.Money) {
+	percent := money.Percent(guv.Amount, buy.Amount)
+	out.Printf("%s %s %s\n", label, color.ByAmount(guv.Float64(), "%+10.2f "+guv.Currency), color.ByAmount(percent, "(%+.2f%%)"))
+}
@@ -0,0 +1,160 @@
+package risk
+
+import (
+	"math"
+	"time"
+)
+
+// TradingDays is the number of trading days per year used to annualize daily figures.
+const TradingDays = 252
+
+const dateLayout = "2006-01-02"
+
+type Series []Point
+
+type Point struct {
+	Date  time.Time
+	Value float64
+}
+
+type Drawdown struct {
+	Value  float64
+	Peak   time.Time
+	Trough time.Time
+}
+
+// Returns calculates the simple returns between consecutive points, each dated at the end of its period.
+func Returns(series Series) Series {
+	if len(series) < 2 {
+		return Series{}
+	}
+	returns := make(Series, 0, len(series)-1)
+	for idx := 1; idx < len(series); idx++ {
+		previous := series[idx-1].Value
+		if previous == 0 {
+			continue
+		}
+		returns = append(returns, Point{Date: series[idx].Date, Value: series[idx].Value/previous - 1})
+	}
+	return returns
+}
+
+// Index chains returns into a value series starting at 100.
+func Index(start time.Time, returns Series) Series {
+	index := make(Series, 0, len(returns)+1)
+	value := 100.0
+	index = append(index, Point{Date: start, Value: value})
+	for _, r := range returns {
+		value *= 1 + r.Value
+		index = append(index, Point{Date: r.Date, Value: value})
+	}
+	return index
+}
+
+func Mean(returns Series) float64 {
+	if len(returns) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, r := range returns {
+		sum += r.Value
+	}
+	return sum / float64(len(returns))
+}
+
+// Volatility is the annualized sample standard deviation of the returns.
+func Volatility(returns Series) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+	mean := Mean(returns)
+	sum := 0.0
+	for _, r := range returns {
+		sum += (r.Value - mean) * (r.Value - mean)
+	}
+	return math.Sqrt(sum/float64(len(returns)-1)) * math.Sqrt(TradingDays)
+}
+
+// MaxDrawdown finds the largest relative decline from a peak to a following trough.
+func MaxDrawdown(series Series) Drawdown {
+	drawdown := Drawdown{}
+	if len(series) == 0 {
+		return drawdown
+	}
+	peak := series[0]
+	for _, p := range series {
+		if p.Value > peak.Value {
+			peak = p
+		}
+		if peak.Value <= 0 {
+			continue
+		}
+		if dd := p.Value/peak.Value - 1; dd < drawdown.Value {
+			drawdown = Drawdown{Value: dd, Peak: peak.Date, Trough: p.Date}
+		}
+	}
+	return drawdown
+}
+
+// Sharpe is the annualized excess return per annualized volatility, riskFreeRate is the yearly rate in percent.
+func Sharpe(returns Series, riskFreeRate float64) (float64, bool) {
+	volatility := Volatility(returns)
+	if volatility == 0 {
+		return 0, false
+	}
+	return (Mean(returns)*TradingDays - riskFreeRate/100) / volatility, true
+}
+
+// Beta of the returns against the benchmark returns, using only dates present in both series.
+func Beta(returns Series, benchmark Series) (float64, bool) {
+	x, y := Align(benchmark, returns)
+	if len(x) < 2 {
+		return 0, false
+	}
+	meanX, meanY := Mean(x), Mean(y)
+	covariance, variance := 0.0, 0.0
+	for idx := range x {
+		covariance += (x[idx].Value - meanX) * (y[idx].Value - meanY)
+		variance += (x[idx].Value - meanX) * (x[idx].Value - meanX)
+	}
+	if variance == 0 {
+		return 0, false
+	}
+	return covariance / variance, true
+}
+
+// Align reduces both series to the dates present in both of them.
+func Align(a Series, b Series) (Series, Series) {
+	byDay := make(map[string]float64, len(b))
+	for _, p := range b {
+		byDay[p.Date.Format(dateLayout)] = p.Value
+	}
+	alignedA := make(Series, 0, len(a))
+	alignedB := make(Series, 0, len(a))
+	for _, p := range a {
+		if value, ok := byDay[p.Date.Format(dateLayout)]; ok {
+			alignedA = append(alignedA, p)
+			alignedB = append(alignedB, Point{Date: p.Date, Value: value})
+		}
+	}
+	return alignedA, alignedB
+}
+
+// Correlation is the pearson correlation of both series, using only dates present in both series.
+func Correlation(a Series, b Series) (float64, bool) {
+	x, y := Align(a, b)
+	if len(x) < 2 {
+		return 0, false
+	}
+	meanX, meanY := Mean(x), Mean(y)
+	covariance, varianceX, varianceY := 0.0, 0.0, 0.0
+	for idx := range x {
+		covariance += (x[idx].Value - meanX) * (y[idx].Value - meanY)
+		varianceX += (x[idx].Value - meanX) * (x[idx].Value - meanX)
+		varianceY += (y[idx].Value - meanY) * (y[idx].Value - meanY)
+	}
+	if varianceX == 0 || varianceY == 0 {
+		return 0, false
+	}
+	return covariance / math.Sqrt(varianceX*varianceY), true
+}
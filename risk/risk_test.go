@@ -0,0 +1,160 @@
+package risk
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func day(d int) time.Time { return time.Date(2026, time.October, d, 0, 0, 0, 0, time.UTC) }
+
+// series dates the values on consecutive days starting with the first of the month.
+func series(values ...float64) Series {
+	s := make(Series, 0, len(values))
+	for idx, value := range values {
+		s = append(s, Point{Date: day(idx + 1), Value: value})
+	}
+	return s
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
+
+func TestReturns(t *testing.T) {
+	tests := []struct {
+		name   string
+		series Series
+		want   []float64
+	}{
+		{"rise and fall", series(100, 110, 99), []float64{0.1, -0.1}},
+		{"zero value skipped", series(0, 50, 100), []float64{1}},
+		{"single point", series(100), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Returns(tt.series)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d returns, want %d", len(got), len(tt.want))
+			}
+			for idx, r := range got {
+				if !near(r.Value, tt.want[idx]) {
+					t.Errorf("return %d = %v, want %v", idx, r.Value, tt.want[idx])
+				}
+			}
+		})
+	}
+}
+
+func TestVolatility(t *testing.T) {
+	tests := []struct {
+		name    string
+		returns Series
+		want    float64
+	}{
+		{"alternating", series(0.01, -0.01, 0.01, -0.01), 0.183303},
+		{"shifted by mean", series(0.02, 0, 0.02, 0), 0.183303},
+		{"constant", series(0.01, 0.01, 0.01), 0},
+		{"single return", series(0.05), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Volatility(tt.returns); !near(got, tt.want) {
+				t.Errorf("Volatility = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxDrawdown(t *testing.T) {
+	tests := []struct {
+		name   string
+		series Series
+		want   Drawdown
+	}{
+		{"largest of two declines", series(100, 120, 90, 130, 110), Drawdown{Value: -0.25, Peak: day(2), Trough: day(3)}},
+		{"trough after new peak", series(100, 90, 150, 75), Drawdown{Value: -0.5, Peak: day(3), Trough: day(4)}},
+		{"rising", series(100, 110, 120), Drawdown{}},
+		{"empty", Series{}, Drawdown{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MaxDrawdown(tt.series)
+			if !near(got.Value, tt.want.Value) || !got.Peak.Equal(tt.want.Peak) || !got.Trough.Equal(tt.want.Trough) {
+				t.Errorf("MaxDrawdown = %v from %s to %s, want %v from %s to %s", got.Value, got.Peak, got.Trough, tt.want.Value, tt.want.Peak, tt.want.Trough)
+			}
+		})
+	}
+}
+
+func TestSharpe(t *testing.T) {
+	tests := []struct {
+		name         string
+		returns      Series
+		riskFreeRate float64
+		want         float64
+		ok           bool
+	}{
+		{"without risk free rate", series(0.02, 0, 0.02, 0), 0, 13.747727, true},
+		{"with risk free rate", series(0.02, 0, 0.02, 0), 2, 13.638618, true},
+		{"zero variance", series(0.01, 0.01, 0.01), 2, 0, false},
+		{"single return", series(0.01), 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Sharpe(tt.returns, tt.riskFreeRate)
+			if ok != tt.ok || !near(got, tt.want) {
+				t.Errorf("Sharpe = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestBetaAndCorrelation(t *testing.T) {
+	benchmark := series(0.01, -0.01, 0.02, 0)
+	tests := []struct {
+		name        string
+		returns     Series
+		benchmark   Series
+		beta        float64
+		correlation float64
+		ok          bool
+	}{
+		{"twice the benchmark", series(0.02, -0.02, 0.04, 0), benchmark, 2, 1, true},
+		{"opposite", series(-0.01, 0.01, -0.02, 0), benchmark, -1, -1, true},
+		{"partly related", series(0.012, 0.003, 0.02, -0.005), benchmark, 0.68, 0.809294, true},
+		{"zero variance", series(0.01, 0.02, 0.03, 0.04), series(0.01, 0.01, 0.01, 0.01), 0, 0, false},
+		{"one aligned point", Series{{Date: day(4), Value: 0.01}, {Date: day(9), Value: 0.02}}, benchmark, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			beta, ok := Beta(tt.returns, tt.benchmark)
+			if ok != tt.ok || !near(beta, tt.beta) {
+				t.Errorf("Beta = %v, %v, want %v, %v", beta, ok, tt.beta, tt.ok)
+			}
+			correlation, ok := Correlation(tt.returns, tt.benchmark)
+			if ok != tt.ok || !near(correlation, tt.correlation) {
+				t.Errorf("Correlation = %v, %v, want %v, %v", correlation, ok, tt.correlation, tt.ok)
+			}
+		})
+	}
+}
+
+func TestAlign(t *testing.T) {
+	a := series(1, 2, 3)
+	b := Series{
+		{Date: day(2).Add(17 * time.Hour), Value: 20},
+		{Date: day(3), Value: 30},
+		{Date: day(4), Value: 40},
+	}
+	alignedA, alignedB := Align(a, b)
+	if len(alignedA) != 2 || len(alignedB) != 2 {
+		t.Fatalf("got %d and %d points, want 2", len(alignedA), len(alignedB))
+	}
+	for idx, want := range []struct {
+		date time.Time
+		a, b float64
+	}{{day(2), 2, 20}, {day(3), 3, 30}} {
+		if !alignedA[idx].Date.Equal(want.date) || !alignedB[idx].Date.Equal(want.date) || alignedA[idx].Value != want.a || alignedB[idx].Value != want.b {
+			t.Errorf("point %d = %v, %v, want %v and %v on %s", idx, alignedA[idx], alignedB[idx], want.a, want.b, want.date)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"kurse/history"
+	"kurse/portfolio"
+	"kurse/risk"
+)
+
+const minRiskSnapshots = 3
+
+func printRisk(out Out, snapshots history.Snapshots, settings portfolio.Risk) {
+	out.Println()
+	if len(snapshots) < minRiskSnapshots {
+		out.Printf("Risiko: mindestens %d Snapshots benötigt, vorhanden: %d (siehe 'kurse snapshot')\n", minRiskSnapshots, len(snapshots))
+		return
+	}
+	out.Printf("Risiko (%s - %s, %d Snapshots, risikofreier Zins %.2f%%", snapshots[0].Day(), snapshots[len(snapshots)-1].Day(), len(snapshots), settings.RiskFreeRate)
+	benchmark := snapshots.BenchmarkPrices()
+	if settings.Benchmark != "" {
+		out.Printf(", Benchmark %s", settings.Benchmark)
+	}
+	out.Println("):")
+	benchmarkReturns := risk.Returns(benchmark)
+	out.Printf("  %-20s %12s %34s %8s %8s\n", "", "Volatilität", "Max. Drawdown", "Sharpe", "Beta")
+
+	depotReturns := snapshots.Returns()
+	printRiskLine(out, "Depot", depotReturns, risk.Index(snapshots[0].Date, depotReturns), benchmarkReturns, settings)
+	for _, symbol := range snapshots.Symbols() {
+		prices := snapshots.Prices(symbol)
+		if len(prices) < minRiskSnapshots {
+			continue
+		}
+		printRiskLine(out, symbol, risk.Returns(prices), prices, benchmarkReturns, settings)
+	}
+}
+
+func printRiskLine(out Out, name string, returns risk.Series, values risk.Series, benchmarkReturns risk.Series, settings portfolio.Risk) {
+	drawdown := risk.MaxDrawdown(values)
+	out.Printf("  %-20s %11.2f%% %8.2f%% ", name, risk.Volatility(returns)*100, drawdown.Value*100)
+	if drawdown.Value < 0 {
+		out.Printf("(%s - %s)", drawdown.Peak.Format("2006-01-02"), drawdown.Trough.Format("2006-01-02"))
+	} else {
+		out.Printf("%25s", "")
+	}
+	if sharpe, ok := risk.Sharpe(returns, settings.RiskFreeRate); ok {
+		out.Printf(" %8.2f", sharpe)
+	} else {
+		out.Printf(" %8s", "-")
+	}
+	if beta, ok := risk.Beta(returns, benchmarkReturns); ok {
+		out.Printf(" %8.2f", beta)
+	} else {
+		out.Printf(" %8s", "-")
+	}
+	out.Println()
+}
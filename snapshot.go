@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"kurse/color"
+	"kurse/exchangerates"
+	"kurse/history"
+	"kurse/money"
+	"log"
+	"math"
+	"strings"
+	"time"
+)
+
+const chartWidth = 50
+
+// checkSnapshot refuses snapshots of positions without price or with quotes or exchange rates from an outdated cache,
+// their values would be recorded in the history as if they were the market values of the day.
+func checkSnapshot(v valuation) error {
+	var symbols []string
+	for _, p := range v.positions {
+		if !p.priced() || !p.cachedSince.IsZero() {
+			symbols = append(symbols, string(p.symbol))
+		}
+	}
+	if len(symbols) > 0 {
+		return fmt.Errorf("not recording a snapshot, no current price for %s", strings.Join(symbols, ", "))
+	}
+	if !v.ratesCachedSince.IsZero() {
+		return fmt.Errorf("not recording a snapshot, exchange rates are outdated since %s", v.ratesCachedSince.Format(dateLayout))
+	}
+	return nil
+}
+
+func takeSnapshot(v valuation) history.Snapshot {
+	snapshot := history.Snapshot{
+		Date:      time.Now(),
+		Currency:  v.totals.value.Currency,
+		Value:     v.totals.value.Amount,
+		Buy:       v.totals.buy.Amount,
+		Dividends: v.totals.dividend.Amount,
+		Positions: make([]history.Position, 0, len(v.positions)),
+	}
+	if v.benchmark != nil {
+		snapshot.Benchmark = &history.Position{
+			Symbol:   string(v.benchmark.Symbol),
+			Price:    v.benchmark.Price,
+			Currency: v.benchmark.Currency,
+		}
+	}
+	for _, p := range v.positions {
+		snapshot.Positions = append(snapshot.Positions, history.Position{
+			Symbol:    string(p.symbol),
+			Count:     p.orderCount,
+			Price:     p.price.Amount,
+			Currency:  p.currency,
+			Value:     p.baseValue.Amount,
+			Buy:       p.orderBuy.Amount,
+			Dividends: p.dividendAmount.Amount,
+		})
+	}
+	return snapshot
+}
+
+// inBaseCurrency converts snapshots recorded in another currency into the given currency at the rates of their day,
+// snapshots without known rates are kept in their currency.
+func inBaseCurrency(snapshots history.Snapshots, fx *exchangerates.History, currency string) history.Snapshots {
+	converted := make(history.Snapshots, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		if snapshot.Currency == currency {
+			converted = append(converted, snapshot)
+			continue
+		}
+		rates, _, ok := fx.RatesAt(snapshot.Date)
+		rate, known := rates.Rate(snapshot.Currency, currency)
+		if !ok || !known {
+			log.Printf("no exchange rate %s/%s for the snapshot of %s, keeping it in %s\n", snapshot.Currency, currency, snapshot.Day(), snapshot.Currency)
+			converted = append(converted, snapshot)
+			continue
+		}
+		convert := func(amount money.Decimal) money.Decimal {
+			return money.New(amount, snapshot.Currency).Convert(rate, currency).Round().Amount
+		}
+		snapshot.Value, snapshot.Buy, snapshot.Dividends = convert(snapshot.Value), convert(snapshot.Buy), convert(snapshot.Dividends)
+		positions := make([]history.Position, 0, len(snapshot.Positions))
+		for _, position := range snapshot.Positions {
+			position.Value, position.Buy, position.Dividends = convert(position.Value), convert(position.Buy), convert(position.Dividends)
+			positions = append(positions, position)
+		}
+		snapshot.Positions = positions
+		snapshot.Currency = currency
+		converted = append(converted, snapshot)
+	}
+	return converted
+}
+
+func printSnapshot(out Out, snapshot history.Snapshot, count int) {
+	out.Printf("Snapshot %s gespeichert (%d Snapshots insgesamt)\n", snapshot.Day(), count)
+	out.Printf("            Wert: %10.2f %s\n", money.New(snapshot.Value, snapshot.Currency).Float64(), snapshot.Currency)
+	out.Printf("            Kauf: %10.2f %s\n", money.New(snapshot.Buy, snapshot.Currency).Float64(), snapshot.Currency)
+	out.Printf("       Dividende: %10.2f %s\n", money.New(snapshot.Dividends, snapshot.Currency).Float64(), snapshot.Currency)
+	out.Printf("  GuV inkl. Div.: %s\n", color.ByAmount(snapshot.GuvInklDividends().Float64(), "%+10.2f "+snapshot.Currency))
+}
+
+func printHistory(out Out, snapshots history.Snapshots) {
+	if len(snapshots) == 0 {
+		out.Println("Keine Snapshots vorhanden, siehe 'kurse snapshot'.")
+		return
+	}
+	out.Printf("%-10s  %12s  %12s  %12s  %12s  %14s\n", "Datum", "Wert", "Kauf", "Dividende", "GuV", "GuV inkl. Div.")
+	for _, snapshot := range snapshots {
+		out.Printf("%-10s  %12.2f  %12.2f  %12.2f  %s  %s %s\n",
+			snapshot.Day(), snapshot.Value.Float64(), snapshot.Buy.Float64(), snapshot.Dividends.Float64(),
+			color.ByAmount(snapshot.Guv().Float64(), "%+12.2f"), color.ByAmount(snapshot.GuvInklDividends().Float64(), "%+14.2f"), snapshot.Currency)
+	}
+}
+
+func printHistoryChart(out Out, snapshots history.Snapshots) {
+	if len(snapshots) == 0 {
+		out.Println("Keine Snapshots vorhanden, siehe 'kurse snapshot'.")
+		return
+	}
+	maxValue := 0.0
+	maxGuv := 0.0
+	for _, snapshot := range snapshots {
+		maxValue = math.Max(maxValue, snapshot.Value.Float64())
+		maxGuv = math.Max(maxGuv, math.Abs(snapshot.GuvInklDividends().Float64()))
+	}
+	out.Println("Wert:")
+	for _, snapshot := range snapshots {
+		value := money.New(snapshot.Value, snapshot.Currency)
+		out.Printf("%s %s %10.2f %s\n", snapshot.Day(), bar(value.Float64(), maxValue), value.Float64(), value.Currency)
+	}
+	out.Println()
+	out.Println("GuV inkl. Div.:")
+	for _, snapshot := range snapshots {
+		guv := snapshot.GuvInklDividends().Float64()
+		guvBar := bar(math.Abs(guv), maxGuv)
+		if guv < 0 {
+			guvBar = color.InRed(guvBar)
+		} else {
+			guvBar = color.InGreen(guvBar)
+		}
+		out.Printf("%s %s %s\n", snapshot.Day(), guvBar, color.ByAmount(guv, "%+10.2f "+snapshot.Currency))
+	}
+}
+
+func bar(value, max float64) string {
+	if max <= 0 {
+		return strings.Repeat(" ", chartWidth)
+	}
+	width := int(math.Round(value / max * chartWidth))
+	return strings.Repeat("█", width) + strings.Repeat(" ", chartWidth-width)
+}
@@ -0,0 +1,49 @@
+package stored
+
+import (
+	"errors"
+	"os"
+	"path"
+)
+
+func Load[T any](application string, store string, mapper func([]byte) (*T, error)) (*T, bool, error) {
+	storeFile, err := ensureStoreFile(application, store)
+	if err != nil {
+		return nil, false, err
+	}
+	data, err := os.ReadFile(storeFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	obj, err := mapper(data)
+	if err != nil {
+		return nil, false, err
+	}
+	return obj, true, nil
+}
+
+func Save[T any](application string, store string, obj *T, mapper func(*T) ([]byte, error)) error {
+	storeFile, err := ensureStoreFile(application, store)
+	if err != nil {
+		return err
+	}
+	data, err := mapper(obj)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(storeFile, data, 0644)
+}
+
+func ensureStoreFile(application, store string) (string, error) {
+	dataDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := path.Join(dataDir, application)
+	if err = os.MkdirAll(dir, 0744); err != nil {
+		return "", err
+	}
+	return path.Join(dir, store), nil
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"fmt"
+	"kurse/bonds"
+	"kurse/exchangerates"
+	"kurse/money"
+	"kurse/portfolio"
+	"kurse/quotes"
+	"log"
+	"sort"
+	"time"
+)
+
+type position struct {
+	symbol                        portfolio.Symbol
+	name                          string
+	provider                      string
+	quoteTime                     time.Time
+	timezone                      string
+	delay                         time.Duration
+	stale                         bool
+	cachedSince                   time.Time
+	marketState                   string
+	extended                      *quotes.ExtendedHours
+	currency                      string
+	price                         money.Money
+	rate                          money.Decimal
+	orderCount                    money.Decimal
+	orderPrice                    money.Money
+	orderProvision                money.Money
+	orderFee                      money.Money
+	orderBuy                      money.Money
+	dividendAmount                money.Money
+	dividendQuellensteuer         money.Money
+	dividendKapitalertragsteuer   money.Money
+	dividendSolidaritaetszuschlag money.Money
+	dividendKirchensteuer         money.Money
+	dividendSteuer                money.Money
+	dividendBrutto                money.Money
+	value                         money.Money
+	baseValue                     money.Money
+	guv                           money.Money
+	guvInklDividend               money.Money
+	lots                          []lot
+	bond                          *bond
+	taxFreeGuv                    money.Money
+	taxableGuv                    money.Money
+	// unpriced names why the position could not be valued, its value is then its cost basis.
+	unpriced string
+}
+
+// lot is a single purchase of a cryptocurrency, its gain is tax-free from taxFreeFrom on.
+type lot struct {
+	date        time.Time
+	count       money.Decimal
+	buy         money.Money
+	value       money.Money
+	guv         money.Money
+	taxFreeFrom time.Time
+}
+
+func (l lot) taxFree(now time.Time) bool { return !now.Before(l.taxFreeFrom) }
+
+// bond holds the bond specific figures of a position.
+type bond struct {
+	portfolio.Bond
+	dirty               bool
+	accruedInterest     money.Money
+	paidAccruedInterest money.Money
+	yield               float64
+	yieldKnown          bool
+	coupons             []coupon
+}
+
+type coupon struct {
+	date   time.Time
+	amount money.Money
+}
+
+type valuation struct {
+	positions []position
+	totals    totals
+	benchmark *quotes.Quote
+	// ratesCachedSince is the time outdated exchange rates were fetched, if fetching them failed
+	ratesCachedSince time.Time
+}
+
+type totals struct {
+	value           money.Money
+	manualValue     money.Money
+	unpricedValue   money.Money
+	buy             money.Money
+	dividend        money.Money
+	dividendSteuer  money.Money
+	dividendBrutto  money.Money
+	guv             money.Money
+	guvInklDividend money.Money
+}
+
+func (p position) manual() bool { return p.provider == quotes.ManualProviderName }
+
+func (p position) converted() bool { return p.rate.Cmp(money.NewFromInt(1)) != 0 }
+
+func (p position) priced() bool { return p.unpriced == "" }
+
+// unpriced returns the positions valued at their cost basis for lack of a quote or an exchange rate.
+func (v valuation) unpriced() []position {
+	var unpriced []position
+	for _, p := range v.positions {
+		if !p.priced() {
+			unpriced = append(unpriced, p)
+		}
+	}
+	return unpriced
+}
+
+func evaluate(stocks map[portfolio.Symbol]portfolio.Stock, fetched quotes.Quotes, rates exchangerates.Rates, fx *exchangerates.History, settings portfolio.Settings) (valuation, error) {
+	symbols := make([]string, 0, len(stocks))
+	for symbol := range stocks {
+		symbols = append(symbols, string(symbol))
+	}
+	sort.Strings(symbols)
+	positions := make([]position, 0, len(symbols))
+	currency := settings.Currency
+	sums := totals{
+		value:          money.Nothing(currency),
+		manualValue:    money.Nothing(currency),
+		unpricedValue:  money.Nothing(currency),
+		buy:            money.Nothing(currency),
+		dividend:       money.Nothing(currency),
+		dividendSteuer: money.Nothing(currency),
+		dividendBrutto: money.Nothing(currency),
+	}
+	for _, symbol := range symbols {
+		stock := stocks[portfolio.Symbol(symbol)]
+		quote, ok := fetched[portfolio.Symbol(symbol)]
+		if !ok {
+			quote = quotes.Quote{Symbol: stock.Symbol, Name: stock.Name, Currency: currency}
+			if quote.Name == "" {
+				quote.Name = symbol
+			}
+		}
+		p, err := evaluatePosition(stock, quote, ok, rates, fx, currency, settings.Bonds)
+		if err != nil {
+			return valuation{}, fmt.Errorf("unable to evaluate %s: %w", symbol, err)
+		}
+		p.stale = p.priced() && !p.manual() && !p.quoteTime.IsZero() && time.Since(p.quoteTime) > settings.Quotes.StaleAfter()
+		if sums.value, err = sums.value.Add(p.baseValue); err != nil {
+			return valuation{}, err
+		}
+		if !p.priced() {
+			if sums.unpricedValue, err = sums.unpricedValue.Add(p.baseValue); err != nil {
+				return valuation{}, err
+			}
+		} else if p.manual() {
+			if sums.manualValue, err = sums.manualValue.Add(p.baseValue); err != nil {
+				return valuation{}, err
+			}
+		}
+		if sums.buy, err = sums.buy.Add(p.orderBuy); err != nil {
+			return valuation{}, err
+		}
+		if sums.dividend, err = sums.dividend.Add(p.dividendAmount); err != nil {
+			return valuation{}, err
+		}
+		if sums.dividendSteuer, err = sums.dividendSteuer.Add(p.dividendSteuer); err != nil {
+			return valuation{}, err
+		}
+		if sums.dividendBrutto, err = sums.dividendBrutto.Add(p.dividendBrutto); err != nil {
+			return valuation{}, err
+		}
+		positions = append(positions, p)
+	}
+	var err error
+	if sums.guv, err = sums.value.Sub(sums.buy); err != nil {
+		return valuation{}, err
+	}
+	if sums.guvInklDividend, err = sums.guv.Add(sums.dividend); err != nil {
+		return valuation{}, err
+	}
+	v := valuation{positions: positions, totals: sums, ratesCachedSince: rates.Stale}
+	if benchmark := settings.Risk.Benchmark; benchmark != "" {
+		if quote, ok := fetched[benchmark]; ok {
+			v.benchmark = &quote
+		} else {
+			log.Printf("no quote for benchmark %s\n", benchmark)
+		}
+	}
+	return v, nil
+}
+
+// evaluatePosition values the position at the quote converted into the base currency. Without a quote or an exchange
+// rate for its currency the position is valued at its cost basis and marked as unpriced.
+func evaluatePosition(stock portfolio.Stock, quote quotes.Quote, quoted bool, rates exchangerates.Rates, fx *exchangerates.History, currency string, bondSettings portfolio.Bonds) (position, error) {
+	var err error
+	p := position{
+		symbol:                        stock.Symbol,
+		name:                          quote.Name,
+		provider:                      quote.Provider,
+		quoteTime:                     quote.Time,
+		timezone:                      quote.Timezone,
+		delay:                         quote.Delay,
+		cachedSince:                   quote.Stale,
+		marketState:                   quote.MarketState,
+		extended:                      quote.Extended,
+		currency:                      quote.Currency,
+		price:                         quote.Money(),
+		rate:                          money.NewFromInt(1),
+		orderPrice:                    money.Nothing(currency),
+		orderProvision:                money.Nothing(currency),
+		orderFee:                      money.Nothing(currency),
+		orderBuy:                      money.Nothing(currency),
+		dividendAmount:                money.Nothing(currency),
+		dividendQuellensteuer:         money.Nothing(currency),
+		dividendKapitalertragsteuer:   money.Nothing(currency),
+		dividendSolidaritaetszuschlag: money.Nothing(currency),
+		dividendKirchensteuer:         money.Nothing(currency),
+		dividendSteuer:                money.Nothing(currency),
+	}
+	rate, ok := rates.Rate(quote.Currency, currency)
+	switch {
+	case !quoted:
+		p.unpriced = "kein Kurs"
+	case !ok:
+		p.unpriced = "kein Umrechnungskurs " + quote.Currency + "/" + currency
+	default:
+		p.rate = rate
+	}
+
+	add := func(sum *money.Money, amount money.Money, date time.Time) (err error) {
+		if amount, err = fx.Convert(amount, currency, date); err != nil {
+			return err
+		}
+		*sum, err = sum.Add(amount)
+		return err
+	}
+	for _, order := range stock.Orders {
+		p.orderCount = p.orderCount.Add(order.Count)
+		if err = add(&p.orderPrice, money.New(order.Price, order.Currency), order.Date); err != nil {
+			return p, err
+		}
+		if err = add(&p.orderProvision, money.New(order.Provision, order.Currency), order.Date); err != nil {
+			return p, err
+		}
+		if err = add(&p.orderFee, money.New(order.Fee, order.Currency), order.Date); err != nil {
+			return p, err
+		}
+	}
+	if p.orderBuy, err = money.Sum(currency, p.orderPrice, p.orderProvision, p.orderFee); err != nil {
+		return p, err
+	}
+	paidAccruedInterest := money.Nothing(currency)
+	if stock.IsBond() && bondSettings.Dirty() {
+		for _, order := range stock.Orders {
+			if err = add(&paidAccruedInterest, money.New(order.AccruedInterest, order.Currency), order.Date); err != nil {
+				return p, err
+			}
+		}
+		if p.orderBuy, err = p.orderBuy.Add(paidAccruedInterest); err != nil {
+			return p, err
+		}
+	}
+	for _, dividend := range stock.Dividends {
+		if err = add(&p.dividendAmount, money.New(dividend.Amount, dividend.Currency), dividend.Date); err != nil {
+			return p, err
+		}
+		if err = add(&p.dividendQuellensteuer, money.New(dividend.Quellensteuer, dividend.Currency), dividend.Date); err != nil {
+			return p, err
+		}
+		if err = add(&p.dividendKapitalertragsteuer, money.New(dividend.Kapitalertragsteuer, dividend.Currency), dividend.Date); err != nil {
+			return p, err
+		}
+		if err = add(&p.dividendSolidaritaetszuschlag, money.New(dividend.Solidaritaetszuschlag, dividend.Currency), dividend.Date); err != nil {
+			return p, err
+		}
+		if err = add(&p.dividendKirchensteuer, money.New(dividend.Kirchensteuer, dividend.Currency), dividend.Date); err != nil {
+			return p, err
+		}
+	}
+	if p.dividendSteuer, err = money.Sum(currency, p.dividendQuellensteuer, p.dividendKapitalertragsteuer, p.dividendSolidaritaetszuschlag, p.dividendKirchensteuer); err != nil {
+		return p, err
+	}
+	if p.dividendBrutto, err = p.dividendAmount.Add(p.dividendSteuer); err != nil {
+		return p, err
+	}
+
+	value := p.price.Mul(p.orderCount)
+	if stock.IsBond() {
+		if value, err = evaluateBond(&p, stock, bondSettings, time.Now()); err != nil {
+			return p, err
+		}
+		p.bond.paidAccruedInterest = paidAccruedInterest
+	}
+	if !p.priced() {
+		log.Printf("%s: %s, using the cost basis as value\n", stock.Symbol, p.unpriced)
+		p.value = value.Round()
+		p.baseValue = p.orderBuy
+		p.guv = money.Nothing(currency)
+		p.guvInklDividend = p.dividendAmount
+		return p, nil
+	}
+	p.value = value.Round()
+	p.baseValue = value.Convert(p.rate, currency).Round()
+	if p.guv, err = p.baseValue.Sub(p.orderBuy); err != nil {
+		return p, err
+	}
+	if p.guvInklDividend, err = p.guv.Add(p.dividendAmount); err != nil {
+		return p, err
+	}
+	if stock.IsCrypto() {
+		if err = evaluateLots(&p, stock, fx, currency, time.Now()); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// evaluateBond calculates accrued interest, yield to maturity and upcoming coupons and returns the value of the bond
+// in its quote currency, the price is quoted in percent of the nominal value.
+func evaluateBond(p *position, stock portfolio.Stock, settings portfolio.Bonds, now time.Time) (money.Money, error) {
+	accrued := bonds.AccruedInterest(*stock.Bond, now)
+	b := &bond{
+		Bond:            *stock.Bond,
+		dirty:           settings.Dirty(),
+		accruedInterest: money.New(accrued.Mul(p.orderCount), p.currency).Round(),
+	}
+	dirtyPrice := p.price.Amount.Mul(bonds.Percent).Add(accrued)
+	b.yield, b.yieldKnown = bonds.YieldToMaturity(*stock.Bond, dirtyPrice.Float64(), now)
+	for _, c := range bonds.Coupons(*stock.Bond, now) {
+		b.coupons = append(b.coupons, coupon{date: c.Date, amount: money.New(c.Rate.Mul(p.orderCount), p.currency).Round()})
+	}
+	p.bond = b
+	value := p.price.Mul(p.orderCount.Mul(bonds.Percent))
+	if b.dirty {
+		return value.Add(b.accruedInterest)
+	}
+	return value, nil
+}
+
+func evaluateLots(p *position, stock portfolio.Stock, fx *exchangerates.History, currency string, now time.Time) error {
+	p.taxFreeGuv = money.Nothing(currency)
+	p.taxableGuv = money.Nothing(currency)
+	for _, order := range stock.Orders {
+		buy, err := fx.Convert(money.New(order.Price.Add(order.Provision).Add(order.Fee), order.Currency), currency, order.Date)
+		if err != nil {
+			return err
+		}
+		l := lot{
+			date:        order.Date,
+			count:       order.Count,
+			buy:         buy,
+			value:       p.price.Mul(order.Count).Convert(p.rate, currency).Round(),
+			taxFreeFrom: order.TaxFreeFrom(),
+		}
+		if l.guv, err = l.value.Sub(l.buy); err != nil {
+			return err
+		}
+		if l.taxFree(now) {
+			p.taxFreeGuv, err = p.taxFreeGuv.Add(l.guv)
+		} else {
+			p.taxableGuv, err = p.taxableGuv.Add(l.guv)
+		}
+		if err != nil {
+			return err
+		}
+		p.lots = append(p.lots, l)
+	}
+	sort.Slice(p.lots, func(i, j int) bool { return p.lots[i].date.Before(p.lots[j].date) })
+	return nil
+}
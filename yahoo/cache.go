@@ -0,0 +1,84 @@
+package yahoo
+
+import (
+	"encoding/json"
+	"kurse/cached"
+	"kurse/markets"
+	"kurse/portfolio"
+	"log"
+	"math"
+	"time"
+)
+
+// cache holds the last fetched result of each symbol together with the time it was fetched.
+type cache map[string]cacheEntry
+
+type cacheEntry struct {
+	Fetched time.Time `json:"fetched"`
+	Result  Result    `json:"result"`
+}
+
+// loadCache returns the cached results of all symbols, an unreadable cache counts as empty.
+func loadCache() cache {
+	c, ok, err := cached.Load("kurse", "yahoo", math.MaxInt64, func(data []byte) (*cache, error) {
+		c := &cache{}
+		return c, json.Unmarshal(data, c)
+	})
+	if err != nil {
+		log.Printf("ignoring unreadable quote cache: %v\n", err)
+		return cache{}
+	}
+	if !ok {
+		return cache{}
+	}
+	for symbol, entry := range *c {
+		// entries of the former cache format holding all results without fetch time
+		if entry.Fetched.IsZero() || entry.Result.Symbol == "" {
+			delete(*c, symbol)
+		}
+	}
+	return *c
+}
+
+// lookup returns the cached results of the symbols still fresh according to the trading hours of their exchange and
+// the symbols to fetch.
+func (c cache) lookup(symbols []portfolio.Symbol, ttl markets.TTL, now time.Time) (Results, []portfolio.Symbol) {
+	results := make(Results, len(symbols))
+	var missing []portfolio.Symbol
+	for _, symbol := range symbols {
+		if entry, ok := c[string(symbol)]; ok && now.Before(entry.expires(ttl)) {
+			results[string(symbol)] = entry.Result
+		} else {
+			missing = append(missing, symbol)
+		}
+	}
+	return results, missing
+}
+
+func (entry cacheEntry) expires(ttl markets.TTL) time.Time {
+	return markets.For(entry.Result.Exchange, entry.Result.ExchangeTimezoneName).Expires(entry.Fetched, ttl)
+}
+
+// stale returns the cached results of the symbols regardless of their age, marked with the time they were fetched.
+func (c cache) stale(symbols []portfolio.Symbol) Results {
+	results := make(Results, len(symbols))
+	for _, symbol := range symbols {
+		if entry, ok := c[string(symbol)]; ok {
+			entry.Result.Stale = entry.Fetched
+			results[string(symbol)] = entry.Result
+		}
+	}
+	return results
+}
+
+func (c cache) add(results Results, now time.Time) {
+	for symbol, result := range results {
+		c[symbol] = cacheEntry{Fetched: now, Result: result}
+	}
+}
+
+func (c cache) save() error {
+	return cached.Save("kurse", "yahoo", &c, func(c *cache) ([]byte, error) {
+		return json.MarshalIndent(c, "", "  ")
+	})
+}
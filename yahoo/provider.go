@@ -0,0 +1,94 @@
+package yahoo
+
+import (
+	"context"
+	"fmt"
+	"kurse/httpclient"
+	"kurse/markets"
+	"kurse/money"
+	"kurse/portfolio"
+	"kurse/quotes"
+	"time"
+)
+
+const ProviderName = "yahoo"
+
+// Provider delivers quotes from the yahoo finance api on RapidAPI.
+type Provider struct {
+	client   *Client
+	ttl      markets.TTL
+	useCache bool
+}
+
+func NewProvider(secrets portfolio.Secrets, batching portfolio.Yahoo, options httpclient.Options, ttl markets.TTL, useCache bool) *Provider {
+	return &Provider{client: NewClient(secrets.YahooHost, secrets.YahooKey, options, batching), ttl: ttl, useCache: useCache}
+}
+
+func (provider *Provider) Name() string { return ProviderName }
+
+func (provider *Provider) FetchQuotes(ctx context.Context, symbols []portfolio.Symbol) (quotes.Quotes, error) {
+	results, err := fetchStocks(ctx, provider.client, symbols, provider.ttl, provider.useCache)
+	if err != nil {
+		return nil, err
+	}
+	fetched := make(quotes.Quotes, len(symbols))
+	for _, symbol := range symbols {
+		if result, ok := results[string(symbol)]; ok {
+			fetched[symbol] = result.Quote()
+		}
+	}
+	return fetched, nil
+}
+
+func (result Result) Quote() quotes.Quote {
+	quote := quotes.Quote{
+		Symbol:        portfolio.Symbol(result.Symbol),
+		Name:          result.LongName,
+		Currency:      result.Currency,
+		Price:         money.NewFromFloat(result.RegularMarketPrice),
+		Change:        money.NewFromFloat(result.RegularMarketChange),
+		ChangePercent: result.RegularMarketChangePercent,
+		MarketState:   result.MarketState,
+		Exchange:      result.FullExchangeName,
+		Timezone:      result.ExchangeTimezoneName,
+		Delay:         time.Duration(result.ExchangeDataDelayedBy) * time.Minute,
+		Provider:      ProviderName,
+	}
+	if result.LongName == "" {
+		quote.Name = result.ShortName
+	} else if result.ShortName != "" {
+		quote.Name = fmt.Sprintf("%s (%s)", result.LongName, result.ShortName)
+	}
+	if result.RegularMarketTime > 0 {
+		quote.Time = time.Unix(int64(result.RegularMarketTime), 0)
+	}
+	quote.Extended = result.extendedHours()
+	quote.Stale = result.Stale
+	return quote
+}
+
+// extendedHours returns the most recent pre- or post-market price if it is newer than the regular market price.
+func (result Result) extendedHours() *quotes.ExtendedHours {
+	var extended *quotes.ExtendedHours
+	latest := result.RegularMarketTime
+	if result.PreMarketPrice > 0 && result.PreMarketTime > latest {
+		extended = &quotes.ExtendedHours{
+			Session:       quotes.PreMarket,
+			Price:         money.NewFromFloat(result.PreMarketPrice),
+			Change:        money.NewFromFloat(result.PreMarketChange),
+			ChangePercent: result.PreMarketChangePercent,
+			Time:          time.Unix(int64(result.PreMarketTime), 0),
+		}
+		latest = result.PreMarketTime
+	}
+	if result.PostMarketPrice > 0 && result.PostMarketTime > latest {
+		extended = &quotes.ExtendedHours{
+			Session:       quotes.PostMarket,
+			Price:         money.NewFromFloat(result.PostMarketPrice),
+			Change:        money.NewFromFloat(result.PostMarketChange),
+			ChangePercent: result.PostMarketChangePercent,
+			Time:          time.Unix(int64(result.PostMarketTime), 0),
+		}
+	}
+	return extended
+}
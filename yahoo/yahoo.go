@@ -1,59 +1,90 @@
 package yahoo
 
 import (
+	"context"
 	"encoding/json"
-	"kurse/cached"
+	"fmt"
+	"kurse/httpclient"
 	"kurse/lang"
+	"kurse/markets"
 	"kurse/portfolio"
 	"log"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
+const (
+	defaultBatchSize   = 50
+	defaultConcurrency = 3
+)
+
 type Client struct {
-	client http.Client
-	host   string
-	key    string
+	client      *httpclient.Client
+	host        string
+	key         string
+	batchSize   int
+	concurrency int
 }
 
-func FetchStocks(symbols []portfolio.Symbol, secrets portfolio.Secrets, useCache bool) Results {
-	var (
-		results Results
-		err     error
-	)
+// fetchStocks returns the cached results of symbols still fresh according to ttl and fetches the others. Symbols of
+// failed requests are taken from the cache regardless of their age, marked as stale. Symbols a successful response
+// omits stay missing, so the next provider is tried.
+func fetchStocks(ctx context.Context, client *Client, symbols []portfolio.Symbol, ttl markets.TTL, useCache bool) (Results, error) {
+	now := time.Now()
+	cache := loadCache()
+	results, missing := Results{}, symbols
 	if useCache {
-		r, ok := cached.Load("kurse", "yahoo", 24*time.Hour, func(data []byte) *Results {
-			r := &Results{}
-			e := json.Unmarshal(data, r)
-			lang.FatalOnError(e)
-			return r
-		})
-		if ok {
-			return *r
+		results, missing = cache.lookup(symbols, ttl, now)
+	}
+	if len(missing) == 0 {
+		return results, nil
+	}
+	fetched, failed, err := client.FetchStocks(ctx, missing)
+	if len(fetched) > 0 {
+		cache.add(fetched, now)
+		if err := cache.save(); err != nil {
+			log.Printf("unable to cache quotes: %v\n", err)
 		}
 	}
-	client := NewClient(secrets.YahooHost, secrets.YahooKey, 10*time.Second)
-	results, err = client.FetchStocks(symbols)
-	lang.FatalOnError(err)
-	cached.Save("kurse", "yahoo", &results, func(results *Results) (data []byte) {
-		data, err = json.MarshalIndent(results, "", "  ")
-		lang.FatalOnError(err)
-		return
-	})
-	return results
+	results.merge(fetched)
+	if stale := cache.stale(failed); len(stale) > 0 {
+		log.Printf("using outdated cached quotes of %d symbols\n", len(stale))
+		results.merge(stale)
+	}
+	if err != nil && len(results) == 0 {
+		return results, err
+	}
+	return results, nil
 }
 
-func NewClient(host string, key string, timeout time.Duration) *Client {
-	return &Client{
-		client: http.Client{Timeout: timeout},
-		host:   host,
-		key:    key,
+func NewClient(host string, key string, options httpclient.Options, batching portfolio.Yahoo) *Client {
+	client := &Client{
+		client:      httpclient.New(ProviderName, options),
+		host:        host,
+		key:         key,
+		batchSize:   batching.BatchSize,
+		concurrency: batching.Concurrency,
 	}
+	if client.batchSize <= 0 {
+		client.batchSize = defaultBatchSize
+	}
+	if client.concurrency <= 0 {
+		client.concurrency = defaultConcurrency
+	}
+	return client
 }
 
 type Results map[string]Result
 
+func (results Results) merge(other Results) Results {
+	for symbol, result := range other {
+		results[symbol] = result
+	}
+	return results
+}
+
 type Result struct {
 	Language                          string  `json:"language"`
 	Region                            string  `json:"region"`
@@ -91,6 +122,14 @@ type Result struct {
 	FullExchangeName                  string  `json:"fullExchangeName"`
 	FinancialCurrency                 string  `json:"financialCurrency"`
 	RegularMarketOpen                 float64 `json:"regularMarketOpen"`
+	PreMarketPrice                    float64 `json:"preMarketPrice"`
+	PreMarketChange                   float64 `json:"preMarketChange"`
+	PreMarketChangePercent            float64 `json:"preMarketChangePercent"`
+	PreMarketTime                     int     `json:"preMarketTime"`
+	PostMarketPrice                   float64 `json:"postMarketPrice"`
+	PostMarketChange                  float64 `json:"postMarketChange"`
+	PostMarketChangePercent           float64 `json:"postMarketChangePercent"`
+	PostMarketTime                    int     `json:"postMarketTime"`
 	AverageDailyVolume3Month          int     `json:"averageDailyVolume3Month"`
 	AverageDailyVolume10Day           int     `json:"averageDailyVolume10Day"`
 	FiftyTwoWeekLowChange             float64 `json:"fiftyTwoWeekLowChange"`
@@ -127,6 +166,8 @@ type Result struct {
 	Tradeable                         bool    `json:"tradeable"`
 	CryptoTradeable                   bool    `json:"cryptoTradeable"`
 	Symbol                            string  `json:"symbol"`
+	// Stale is the time the result was fetched if it is taken from the cache after a failed fetch
+	Stale time.Time `json:"-"`
 }
 
 type response struct {
@@ -142,18 +183,69 @@ type meta struct {
 	Version       string    `json:"version"`
 }
 
-func (client *Client) FetchStocks(symbols []portfolio.Symbol) (Results, error) {
-	return client.fetchStocks(symbols)
+// FetchStocks fetches the symbols in concurrent batches and returns the results and the symbols of failed batches, the
+// error is only returned if all batches failed.
+func (client *Client) FetchStocks(ctx context.Context, symbols []portfolio.Symbol) (Results, []portfolio.Symbol, error) {
+	var (
+		wg        sync.WaitGroup
+		mutex     sync.Mutex
+		firstErr  error
+		failed    []portfolio.Symbol
+		failures  int
+		results   = make(Results, len(symbols))
+		batches   = batch(symbols, client.batchSize)
+		semaphore = make(chan struct{}, client.concurrency)
+	)
+	for _, symbolBatch := range batches {
+		wg.Add(1)
+		go func(symbolBatch []portfolio.Symbol) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			batchResults, err := client.fetchStocks(ctx, symbolBatch)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				log.Printf("unable to fetch %v: %v\n", symbolBatch, err)
+				if firstErr == nil {
+					firstErr = err
+				}
+				failed = append(failed, symbolBatch...)
+				failures++
+				return
+			}
+			for symbol, result := range batchResults {
+				results[symbol] = result
+			}
+		}(symbolBatch)
+	}
+	wg.Wait()
+	if failures > 0 && failures == len(batches) {
+		return nil, failed, firstErr
+	}
+	return results, failed, nil
+}
+
+func batch(symbols []portfolio.Symbol, size int) [][]portfolio.Symbol {
+	batches := make([][]portfolio.Symbol, 0, (len(symbols)+size-1)/size)
+	for start := 0; start < len(symbols); start += size {
+		end := start + size
+		if end > len(symbols) {
+			end = len(symbols)
+		}
+		batches = append(batches, symbols[start:end])
+	}
+	return batches
 }
 
-func (client *Client) fetchStocks(symbols []portfolio.Symbol) (Results, error) {
+func (client *Client) fetchStocks(ctx context.Context, symbols []portfolio.Symbol) (Results, error) {
 	var (
 		rq  *http.Request
 		rs  *http.Response
 		err error
 	)
 	ticker := sliceOfSymbolsToQueryParam(symbols)
-	rq, err = http.NewRequest(http.MethodGet, "https://yahoo-finance15.p.rapidapi.com/api/v1/markets/stock/quotes?ticker="+ticker, nil)
+	rq, err = http.NewRequestWithContext(ctx, http.MethodGet, "https://yahoo-finance15.p.rapidapi.com/api/v1/markets/stock/quotes?ticker="+ticker, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -165,6 +257,9 @@ func (client *Client) fetchStocks(symbols []portfolio.Symbol) (Results, error) {
 		return nil, err
 	}
 	defer lang.Close(rs.Body, "unable to close response body")
+	if rs.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with %s", ProviderName, rs.Status)
+	}
 	var resp = response{}
 	err = json.NewDecoder(rs.Body).Decode(&resp)
 	if err != nil {